@@ -0,0 +1,139 @@
+package vec32
+
+import (
+	"math/rand"
+	"testing"
+)
+
+// every assembly routine is compared with its Go twin, with the purego tag
+// this compares Go with Go
+
+func randomCoord(rnd *rand.Rand) float32 {
+	switch rnd.Intn(10) {
+	case 0:
+		return 0
+	case 1:
+		return float32(rnd.Intn(5) - 2)
+	}
+	return rnd.Float32()*20 - 10
+}
+
+func randomVec3(rnd *rand.Rand) Vec3 {
+	return NewVec3(randomCoord(rnd), randomCoord(rnd), randomCoord(rnd))
+}
+
+func randomOrthoBox(rnd *rand.Rand) OrthoBox {
+	bb := ORTHO_EMPTY
+	p0, p1 := randomVec3(rnd), randomVec3(rnd)
+	bb.Add(&OrthoBox{p0, p0})
+	bb.Add(&OrthoBox{p1, p1})
+	return bb
+}
+
+func testAsmVec3(t *testing.T, name string, exp, cur *Vec3) {
+	if !AlmostEqual3(exp, cur) || cur.pad != 0 {
+		t.Errorf("%s differs from Go - expected %s got %s (pad %g)",
+			name, exp.String(), cur.String(), cur.pad)
+	}
+}
+
+func TestAsmR3(t *testing.T) {
+	rnd := rand.New(rand.NewSource(41))
+	for i := 0; i < 1000; i++ {
+		a, b := randomVec3(rnd), randomVec3(rnd)
+		testFloat(t, "LengthR3()", lengthR3(&a), LengthR3(&a))
+
+		var exp, cur Vec3
+		add3(&a, &b, &exp)
+		Add3(&a, &b, &cur)
+		testAsmVec3(t, "Add3()", &exp, &cur)
+
+		bb1, bb2 := randomOrthoBox(rnd), randomOrthoBox(rnd)
+		expBB, curBB := bb1, bb1
+		orthoBoxAdd(&expBB, &bb2)
+		OrthoBoxAdd(&curBB, &bb2)
+		testAsmVec3(t, "OrthoBoxAdd() P0", &expBB.P0, &curBB.P0)
+		testAsmVec3(t, "OrthoBoxAdd() P1", &expBB.P1, &curBB.P1)
+
+		var m Mat4
+		for col := 0; col < 4; col++ {
+			for row := 0; row < 4; row++ {
+				m[col][row] = randomCoord(rnd)
+			}
+		}
+		transformPoint3(&m, &a, &exp)
+		TransformPoint3(&m, &a, &cur)
+		testAsmVec3(t, "TransformPoint3()", &exp, &cur)
+	}
+}
+
+func TestAsmOrthoBoxIntersect(t *testing.T) {
+	rnd := rand.New(rand.NewSource(43))
+	for i := 0; i < 1000; i++ {
+		bb := randomOrthoBox(rnd)
+		// origins on the slabs and axis parallel rays lead to NaN
+		p0 := randomVec3(rnd)
+		if rnd.Intn(4) == 0 {
+			p0.X = bb.P0.X
+		}
+		r := Ray{P0: p0, N: randomVec3(rnd)}
+		var ri RayInv
+		r.Inverse(&ri)
+		expMin, expMax := orthoBoxIntersect(&bb, &ri.P0, &ri.Inv)
+		curMin, curMax := OrthoBoxIntersect(&bb, &ri.P0, &ri.Inv)
+		if expMin != curMin || expMax != curMax {
+			t.Errorf("OrthoBoxIntersect() differs from Go for %s, ray %s %s - expected %g, %g got %g, %g",
+				bb.String(), r.P0.String(), r.N.String(), expMin, expMax, curMin, curMax)
+		}
+	}
+}
+
+func TestAsmBatch(t *testing.T) {
+	rnd := rand.New(rand.NewSource(47))
+	for i := 0; i < 100; i++ {
+		n := rnd.Intn(20)
+		a, b := make([]Vec3, n), make([]Vec3, n)
+		for j := range a {
+			a[j], b[j] = randomVec3(rnd), randomVec3(rnd)
+			// normalizing the zero vector gives NaN
+			if a[j].LengthSq() == 0 {
+				a[j].X = 1
+			}
+		}
+		exp, cur := make([]Vec3, n), make([]Vec3, n)
+		s := randomCoord(rnd)
+		m := NewMat4Rotate(&Vec3{X: 1, Y: 2, Z: 3}, s)
+
+		for _, k := range []struct {
+			name string
+			goFn func()
+			asm  func()
+		}{
+			{"addSlice3SIMD4()", func() { addSlice3(a, b, exp) }, func() { AddSlice3(a, b, cur) }},
+			{"scaleSlice3SIMD4()", func() { scaleSlice3(a, s, exp) }, func() { ScaleSlice3(a, s, cur) }},
+			{"transformSlice3SIMD4()", func() { transformSlice3(&m, a, exp) }, func() { TransformSlice3(&m, a, cur) }},
+			{"normalizeSlice3SIMD4()", func() { normalizeSlice3(a, exp) }, func() { NormalizeSlice3(a, cur) }},
+		} {
+			k.goFn()
+			k.asm()
+			for j := range exp {
+				testAsmVec3(t, k.name, &exp[j], &cur[j])
+			}
+		}
+		expBB, curBB := ORTHO_EMPTY, ORTHO_EMPTY
+		boundsSlice3(a, &expBB)
+		BoundsSlice3(a, &curBB)
+		testBounds(t, "boundsSlice3SIMD4()", &expBB, &curBB)
+
+		sa := NewVec3SoAFromSlice(a)
+		c := randomVec3(rnd)
+		expDot, curDot := make([]float32, n), make([]float32, n)
+		dotSoA3(sa, &c, expDot, 0, n)
+		DotSoA3(sa, &c, curDot)
+		testFloats(t, "dotSoA3SIMD4()", expDot, curDot)
+		expSoA, curSoA := NewVec3SoA(n), NewVec3SoA(n)
+		transformSoA3(&m, sa, expSoA, 0, n)
+		TransformSoA3(&m, sa, curSoA)
+		testVec3s(t, "transformSoA3SIMD4()", expSoA.AppendTo(nil), curSoA.AppendTo(nil))
+	}
+}
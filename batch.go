@@ -0,0 +1,397 @@
+package vec32
+
+// Batch operations
+//
+// The functions below work on many vectors at once, either stored as a
+// slice of Vec3 (array of structures) or as Vec3SoA (structure of arrays).
+// Outputs may be the same slices as the inputs. They panic, if an output
+// is shorter than the input.
+
+const errShortSlice = "vec32: slice too short"
+
+// Vectors stored as structure of arrays, all slices have the same length
+type Vec3SoA struct {
+	X, Y, Z []float32
+}
+
+// Create a new structure of arrays for n vectors
+func NewVec3SoA(n int) *Vec3SoA {
+	return &Vec3SoA{
+		X: make([]float32, n),
+		Y: make([]float32, n),
+		Z: make([]float32, n),
+	}
+}
+
+// Create a new structure of arrays from a slice of vectors
+func NewVec3SoAFromSlice(v []Vec3) *Vec3SoA {
+	s := NewVec3SoA(len(v))
+	for i := range v {
+		s.X[i] = v[i].X
+		s.Y[i] = v[i].Y
+		s.Z[i] = v[i].Z
+	}
+	return s
+}
+
+// Number of vectors
+func (s *Vec3SoA) Len() int {
+	return len(s.X)
+}
+
+// Get the vector i
+func (s *Vec3SoA) At(i int) Vec3 {
+	return Vec3{s.X[i], s.Y[i], s.Z[i], 0}
+}
+
+// Set the vector i
+func (s *Vec3SoA) Set(i int, v *Vec3) {
+	s.X[i] = v.X
+	s.Y[i] = v.Y
+	s.Z[i] = v.Z
+}
+
+// Append all vectors to a slice
+func (s *Vec3SoA) AppendTo(v []Vec3) []Vec3 {
+	for i := range s.X {
+		v = append(v, Vec3{s.X[i], s.Y[i], s.Z[i], 0})
+	}
+	return v
+}
+
+func (s *Vec3SoA) check(n int) {
+	if len(s.X) < n || len(s.Y) < n || len(s.Z) < n {
+		panic(errShortSlice)
+	}
+}
+
+// Add two slices of vectors (out[i] = a[i] + b[i])
+func AddSlice3(a, b, out []Vec3) {
+	if len(b) < len(a) || len(out) < len(a) {
+		panic(errShortSlice)
+	}
+	if simdLevel < SIMD_SSE || len(a) == 0 {
+		addSlice3(a, b, out)
+		return
+	}
+	addSlice3SIMD4(&a[0], &b[0], &out[0], len(a))
+}
+
+func addSlice3(a, b, out []Vec3) {
+	for i := range a {
+		add3(&a[i], &b[i], &out[i])
+	}
+}
+
+// Scale a slice of vectors (out[i] = v[i] * s)
+func ScaleSlice3(v []Vec3, s float32, out []Vec3) {
+	if len(out) < len(v) {
+		panic(errShortSlice)
+	}
+	if simdLevel < SIMD_SSE || len(v) == 0 {
+		scaleSlice3(v, s, out)
+		return
+	}
+	scaleSlice3SIMD4(&v[0], s, &out[0], len(v))
+}
+
+func scaleSlice3(v []Vec3, s float32, out []Vec3) {
+	for i := range v {
+		Scale3(&v[i], s, &out[i])
+	}
+}
+
+// Dot product of a slice of vectors with a constant one (out[i] = v[i] * c)
+//
+// There is no SSE version, the horizontal sums make it slower than the
+// plain loop. Use DotSoA3() for speed.
+func DotSlice3(v []Vec3, c *Vec3, out []float32) {
+	if len(out) < len(v) {
+		panic(errShortSlice)
+	}
+	dotSlice3(v, c, out)
+}
+
+func dotSlice3(v []Vec3, c *Vec3, out []float32) {
+	for i := range v {
+		out[i] = v[i].X*c.X + v[i].Y*c.Y + v[i].Z*c.Z
+	}
+}
+
+// Transform a slice of points by an affine matrix (see TransformPoint3())
+func TransformSlice3(m *Mat4, v, out []Vec3) {
+	if len(out) < len(v) {
+		panic(errShortSlice)
+	}
+	if simdLevel < SIMD_SSE || len(v) == 0 {
+		transformSlice3(m, v, out)
+		return
+	}
+	transformSlice3SIMD4(m, &v[0], &out[0], len(v))
+}
+
+func transformSlice3(m *Mat4, v, out []Vec3) {
+	for i := range v {
+		transformPoint3(m, &v[i], &out[i])
+	}
+}
+
+// Normalize a slice of vectors
+func NormalizeSlice3(v, out []Vec3) {
+	if len(out) < len(v) {
+		panic(errShortSlice)
+	}
+	if simdLevel < SIMD_SSE || len(v) == 0 {
+		normalizeSlice3(v, out)
+		return
+	}
+	normalizeSlice3SIMD4(&v[0], &out[0], len(v))
+}
+
+func normalizeSlice3(v, out []Vec3) {
+	for i := range v {
+		p := &v[i]
+		n := Sqrt(p.X*p.X + p.Y*p.Y + p.Z*p.Z)
+		out[i] = Vec3{p.X / n, p.Y / n, p.Z / n, 0}
+	}
+}
+
+// Extend a box to contain all points of a slice
+//
+// Start with ORTHO_EMPTY to get the bounding box of the points.
+func BoundsSlice3(v []Vec3, bb *OrthoBox) {
+	if simdLevel < SIMD_SSE || len(v) == 0 {
+		boundsSlice3(v, bb)
+		return
+	}
+	boundsSlice3SIMD4(&v[0], len(v), bb)
+}
+
+func boundsSlice3(v []Vec3, bb *OrthoBox) {
+	for i := range v {
+		Min3(&bb.P0, &v[i], &bb.P0)
+		Max3(&bb.P1, &v[i], &bb.P1)
+	}
+}
+
+// Add two structures of arrays (out[i] = a[i] + b[i])
+func AddSoA3(a, b, out *Vec3SoA) {
+	n := a.Len()
+	b.check(n)
+	out.check(n)
+	for i := 0; i < n; i++ {
+		out.X[i] = a.X[i] + b.X[i]
+		out.Y[i] = a.Y[i] + b.Y[i]
+		out.Z[i] = a.Z[i] + b.Z[i]
+	}
+}
+
+// Scale a structure of arrays (out[i] = v[i] * s)
+func ScaleSoA3(v *Vec3SoA, s float32, out *Vec3SoA) {
+	n := v.Len()
+	out.check(n)
+	for i := 0; i < n; i++ {
+		out.X[i] = v.X[i] * s
+		out.Y[i] = v.Y[i] * s
+		out.Z[i] = v.Z[i] * s
+	}
+}
+
+// Dot product of a structure of arrays with a constant vector
+func DotSoA3(v *Vec3SoA, c *Vec3, out []float32) {
+	n := v.Len()
+	v.check(n)
+	if len(out) < n {
+		panic(errShortSlice)
+	}
+	// the SIMD kernels handle multiples of their width, Go the rest
+	done := 0
+	switch {
+	case simdLevel >= SIMD_AVX2 && n >= 8:
+		done = n &^ 7
+		dotSoA3SIMD8(&v.X[0], &v.Y[0], &v.Z[0], c, &out[0], done)
+	case simdLevel >= SIMD_SSE && n >= 4:
+		done = n &^ 3
+		dotSoA3SIMD4(&v.X[0], &v.Y[0], &v.Z[0], c, &out[0], done)
+	}
+	dotSoA3(v, c, out, done, n)
+}
+
+func dotSoA3(v *Vec3SoA, c *Vec3, out []float32, start, end int) {
+	for i := start; i < end; i++ {
+		out[i] = v.X[i]*c.X + v.Y[i]*c.Y + v.Z[i]*c.Z
+	}
+}
+
+// Transform a structure of arrays of points by an affine matrix
+func TransformSoA3(m *Mat4, v, out *Vec3SoA) {
+	n := v.Len()
+	v.check(n)
+	out.check(n)
+	done := 0
+	if simdLevel >= SIMD_SSE && n >= 4 {
+		done = n &^ 3
+		transformSoA3SIMD4(m, &v.X[0], &v.Y[0], &v.Z[0],
+			&out.X[0], &out.Y[0], &out.Z[0], done)
+	}
+	transformSoA3(m, v, out, done, n)
+}
+
+func transformSoA3(m *Mat4, v, out *Vec3SoA, start, end int) {
+	for i := start; i < end; i++ {
+		x, y, z := v.X[i], v.Y[i], v.Z[i]
+		out.X[i] = m[0][0]*x + m[1][0]*y + m[2][0]*z + m[3][0]
+		out.Y[i] = m[0][1]*x + m[1][1]*y + m[2][1]*z + m[3][1]
+		out.Z[i] = m[0][2]*x + m[1][2]*y + m[2][2]*z + m[3][2]
+	}
+}
+
+// Normalize a structure of arrays
+func NormalizeSoA3(v, out *Vec3SoA) {
+	n := v.Len()
+	v.check(n)
+	out.check(n)
+	for i := 0; i < n; i++ {
+		x, y, z := v.X[i], v.Y[i], v.Z[i]
+		l := Sqrt(x*x + y*y + z*z)
+		out.X[i] = x / l
+		out.Y[i] = y / l
+		out.Z[i] = z / l
+	}
+}
+
+// Extend a box to contain all points of a structure of arrays
+//
+// Start with ORTHO_EMPTY to get the bounding box of the points.
+func BoundsSoA3(v *Vec3SoA, bb *OrthoBox) {
+	n := v.Len()
+	v.check(n)
+	for i := 0; i < n; i++ {
+		bb.P0.X = Min(bb.P0.X, v.X[i])
+		bb.P0.Y = Min(bb.P0.Y, v.Y[i])
+		bb.P0.Z = Min(bb.P0.Z, v.Z[i])
+		bb.P1.X = Max(bb.P1.X, v.X[i])
+		bb.P1.Y = Max(bb.P1.Y, v.Y[i])
+		bb.P1.Z = Max(bb.P1.Z, v.Z[i])
+	}
+}
+
+// Cross product of two structures of arrays (out[i] = a[i] x b[i])
+func CrossSoA3(a, b, out *Vec3SoA) {
+	n := a.Len()
+	a.check(n)
+	b.check(n)
+	out.check(n)
+	done := 0
+	switch {
+	case simdLevel >= SIMD_AVX2 && n >= 8:
+		done = n &^ 7
+		crossSoA3SIMD8(&a.X[0], &a.Y[0], &a.Z[0], &b.X[0], &b.Y[0], &b.Z[0],
+			&out.X[0], &out.Y[0], &out.Z[0], done)
+	case simdLevel >= SIMD_SSE && n >= 4:
+		done = n &^ 3
+		crossSoA3SIMD4(&a.X[0], &a.Y[0], &a.Z[0], &b.X[0], &b.Y[0], &b.Z[0],
+			&out.X[0], &out.Y[0], &out.Z[0], done)
+	}
+	crossSoA3(a, b, out, done, n)
+}
+
+func crossSoA3(a, b, out *Vec3SoA, start, end int) {
+	for i := start; i < end; i++ {
+		ax, ay, az := a.X[i], a.Y[i], a.Z[i]
+		bx, by, bz := b.X[i], b.Y[i], b.Z[i]
+		out.X[i] = ay*bz - az*by
+		out.Y[i] = az*bx - ax*bz
+		out.Z[i] = ax*by - ay*bx
+	}
+}
+
+// Euklidian length of the vectors of a structure of arrays
+func LengthSoA3(v *Vec3SoA, out []float32) {
+	n := v.Len()
+	v.check(n)
+	if len(out) < n {
+		panic(errShortSlice)
+	}
+	done := 0
+	switch {
+	case simdLevel >= SIMD_AVX2 && n >= 8:
+		done = n &^ 7
+		lengthSoA3SIMD8(&v.X[0], &v.Y[0], &v.Z[0], &out[0], done)
+	case simdLevel >= SIMD_SSE && n >= 4:
+		done = n &^ 3
+		lengthSoA3SIMD4(&v.X[0], &v.Y[0], &v.Z[0], &out[0], done)
+	}
+	lengthSoA3(v, out, done, n)
+}
+
+func lengthSoA3(v *Vec3SoA, out []float32, start, end int) {
+	for i := start; i < end; i++ {
+		x, y, z := v.X[i], v.Y[i], v.Z[i]
+		out[i] = Sqrt(x*x + y*y + z*z)
+	}
+}
+
+// Extend a box to contain all boxes of a slice (see OrthoBox.Add())
+func BoundsBoxes(boxes []OrthoBox, bb *OrthoBox) {
+	if len(boxes) == 0 {
+		return
+	}
+	switch {
+	case simdLevel >= SIMD_AVX2:
+		boundsBoxesSIMD8(&boxes[0], len(boxes), bb)
+	case simdLevel >= SIMD_SSE:
+		boundsBoxesSIMD4(&boxes[0], len(boxes), bb)
+	default:
+		boundsBoxes(boxes, bb)
+	}
+}
+
+func boundsBoxes(boxes []OrthoBox, bb *OrthoBox) {
+	for i := range boxes {
+		orthoBoxAdd(bb, &boxes[i])
+	}
+}
+
+// 8 boxes in structure of arrays layout for OrthoBoxIntersect8()
+//
+// P0[axis][i] and P1[axis][i] are the corners of box i.
+type OrthoBox8 struct {
+	P0, P1 [3][8]float32
+}
+
+// Set the box i
+func (b *OrthoBox8) Set(i int, bb *OrthoBox) {
+	b.P0[0][i], b.P0[1][i], b.P0[2][i] = bb.P0.X, bb.P0.Y, bb.P0.Z
+	b.P1[0][i], b.P1[1][i], b.P1[2][i] = bb.P1.X, bb.P1.Y, bb.P1.Z
+}
+
+// Get the box i
+func (b *OrthoBox8) Box(i int) OrthoBox {
+	return OrthoBox{
+		NewVec3(b.P0[0][i], b.P0[1][i], b.P0[2][i]),
+		NewVec3(b.P1[0][i], b.P1[1][i], b.P1[2][i]),
+	}
+}
+
+// ray-box-intersection of 8 boxes at once
+//
+// p0 is the origin of the ray, inv the reciprocal of its direction. The
+// results for box i are the same as OrthoBoxIntersect() would return.
+func OrthoBoxIntersect8(b *OrthoBox8, p0, inv *Vec3, tmin, tmax *[8]float32) {
+	switch {
+	case simdLevel >= SIMD_AVX2:
+		orthoBoxIntersect8SIMD8(b, p0, inv, tmin, tmax)
+	case simdLevel >= SIMD_SSE:
+		orthoBoxIntersect8SIMD4(b, p0, inv, tmin, tmax)
+	default:
+		orthoBoxIntersect8(b, p0, inv, tmin, tmax)
+	}
+}
+
+func orthoBoxIntersect8(b *OrthoBox8, p0, inv *Vec3, tmin, tmax *[8]float32) {
+	for i := 0; i < 8; i++ {
+		bb := b.Box(i)
+		tmin[i], tmax[i] = orthoBoxIntersect(&bb, p0, inv)
+	}
+}
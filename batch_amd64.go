@@ -0,0 +1,56 @@
+//go:build !purego
+
+package vec32
+
+// 4-wide kernels of batch.go, SSE (batch_amd64.s). See batch_noasm.go for
+// the Go versions
+
+//go:noescape
+func addSlice3SIMD4(a, b, out *Vec3, n int)
+
+//go:noescape
+func scaleSlice3SIMD4(v *Vec3, s float32, out *Vec3, n int)
+
+//go:noescape
+func transformSlice3SIMD4(m *Mat4, v, out *Vec3, n int)
+
+//go:noescape
+func normalizeSlice3SIMD4(v, out *Vec3, n int)
+
+//go:noescape
+func boundsSlice3SIMD4(v *Vec3, n int, bb *OrthoBox)
+
+//go:noescape
+func dotSoA3SIMD4(x, y, z *float32, c *Vec3, out *float32, n int)
+
+//go:noescape
+func transformSoA3SIMD4(m *Mat4, x, y, z, ox, oy, oz *float32, n int)
+
+//go:noescape
+func crossSoA3SIMD4(ax, ay, az, bx, by, bz, ox, oy, oz *float32, n int)
+
+//go:noescape
+func lengthSoA3SIMD4(x, y, z, out *float32, n int)
+
+//go:noescape
+func boundsBoxesSIMD4(boxes *OrthoBox, n int, bb *OrthoBox)
+
+//go:noescape
+func orthoBoxIntersect8SIMD4(b *OrthoBox8, p0, inv *Vec3, tmin, tmax *[8]float32)
+
+// 8-wide kernels, AVX2 (batch_avx2_amd64.s), only used if the CPU supports them
+
+//go:noescape
+func dotSoA3SIMD8(x, y, z *float32, c *Vec3, out *float32, n int)
+
+//go:noescape
+func crossSoA3SIMD8(ax, ay, az, bx, by, bz, ox, oy, oz *float32, n int)
+
+//go:noescape
+func lengthSoA3SIMD8(x, y, z, out *float32, n int)
+
+//go:noescape
+func boundsBoxesSIMD8(boxes *OrthoBox, n int, bb *OrthoBox)
+
+//go:noescape
+func orthoBoxIntersect8SIMD8(b *OrthoBox8, p0, inv *Vec3, tmin, tmax *[8]float32)
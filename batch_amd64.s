@@ -0,0 +1,461 @@
+//go:build !purego
+
+// SSE kernels for batch.go
+//
+// Vec3 is 16 bytes, so one vector fits into an XMM register. The pad lane
+// is cleared with a mask (all bits set but the upper 4 bytes) where it
+// could become non-zero.
+
+TEXT ·addSlice3SIMD4(SB),7,$0-32
+	MOVQ	a+0(FP), AX
+	MOVQ	b+8(FP), BX
+	MOVQ	out+16(FP), CX
+	MOVQ	n+24(FP), DX
+	TESTQ	DX, DX
+	JLE	done
+loop:
+	MOVUPS	(AX), X0
+	MOVUPS	(BX), X1
+	ADDPS	X1, X0
+	MOVUPS	X0, (CX)
+	ADDQ	$16, AX
+	ADDQ	$16, BX
+	ADDQ	$16, CX
+	DECQ	DX
+	JNZ	loop
+done:
+	RET
+
+TEXT ·scaleSlice3SIMD4(SB),7,$0-32
+	MOVQ	v+0(FP), AX
+	MOVSS	s+8(FP), X2
+	SHUFPS	$0x00, X2, X2
+	MOVQ	out+16(FP), CX
+	MOVQ	n+24(FP), DX
+	PCMPEQL	X7, X7
+	PSRLDQ	$4, X7
+	TESTQ	DX, DX
+	JLE	done
+loop:
+	MOVUPS	(AX), X0
+	MULPS	X2, X0
+	ANDPS	X7, X0
+	MOVUPS	X0, (CX)
+	ADDQ	$16, AX
+	ADDQ	$16, CX
+	DECQ	DX
+	JNZ	loop
+done:
+	RET
+
+// like TransformPoint3, but the columns are loaded only once
+TEXT ·transformSlice3SIMD4(SB),7,$0-32
+	MOVQ	m+0(FP), AX
+	MOVQ	v+8(FP), BX
+	MOVQ	out+16(FP), CX
+	MOVQ	n+24(FP), DX
+	MOVUPS	(AX), X4
+	MOVUPS	16(AX), X5
+	MOVUPS	32(AX), X6
+	MOVUPS	48(AX), X7
+	PCMPEQL	X8, X8
+	PSRLDQ	$4, X8
+	TESTQ	DX, DX
+	JLE	done
+loop:
+	MOVSS	(BX), X0
+	MOVSS	4(BX), X1
+	MOVSS	8(BX), X2
+	SHUFPS	$0x00, X0, X0
+	SHUFPS	$0x00, X1, X1
+	SHUFPS	$0x00, X2, X2
+	MULPS	X4, X0
+	MULPS	X5, X1
+	MULPS	X6, X2
+	ADDPS	X1, X0
+	ADDPS	X2, X0
+	ADDPS	X7, X0
+	ANDPS	X8, X0
+	MOVUPS	X0, (CX)
+	ADDQ	$16, BX
+	ADDQ	$16, CX
+	DECQ	DX
+	JNZ	loop
+done:
+	RET
+
+TEXT ·normalizeSlice3SIMD4(SB),7,$0-24
+	MOVQ	v+0(FP), AX
+	MOVQ	out+8(FP), CX
+	MOVQ	n+16(FP), DX
+	PCMPEQL	X7, X7
+	PSRLDQ	$4, X7
+	TESTQ	DX, DX
+	JLE	done
+loop:
+	MOVUPS	(AX), X0
+	MOVAPS	X0, X1
+	MULPS	X1, X1
+	MOVAPS	X1, X2
+	MOVAPS	X1, X3
+	SHUFPS	$0x55, X2, X2
+	SHUFPS	$0xaa, X3, X3
+	ADDSS	X2, X1
+	ADDSS	X3, X1
+	SQRTSS	X1, X1
+	SHUFPS	$0x00, X1, X1
+	DIVPS	X1, X0
+	ANDPS	X7, X0
+	MOVUPS	X0, (CX)
+	ADDQ	$16, AX
+	ADDQ	$16, CX
+	DECQ	DX
+	JNZ	loop
+done:
+	RET
+
+TEXT ·boundsSlice3SIMD4(SB),7,$0-24
+	MOVQ	v+0(FP), AX
+	MOVQ	n+8(FP), DX
+	MOVQ	bb+16(FP), CX
+	MOVUPS	(CX), X0
+	MOVUPS	16(CX), X1
+	TESTQ	DX, DX
+	JLE	done
+loop:
+	MOVUPS	(AX), X2
+	MINPS	X2, X0
+	MAXPS	X2, X1
+	ADDQ	$16, AX
+	DECQ	DX
+	JNZ	loop
+done:
+	MOVUPS	X0, (CX)
+	MOVUPS	X1, 16(CX)
+	RET
+
+// 4 vectors at a time, n must be a multiple of 4
+TEXT ·dotSoA3SIMD4(SB),7,$0-48
+	MOVQ	x+0(FP), AX
+	MOVQ	y+8(FP), BX
+	MOVQ	z+16(FP), CX
+	MOVQ	c+24(FP), SI
+	MOVQ	out+32(FP), DI
+	MOVQ	n+40(FP), DX
+	MOVSS	(SI), X4
+	MOVSS	4(SI), X5
+	MOVSS	8(SI), X6
+	SHUFPS	$0x00, X4, X4
+	SHUFPS	$0x00, X5, X5
+	SHUFPS	$0x00, X6, X6
+	TESTQ	DX, DX
+	JLE	done
+loop:
+	MOVUPS	(AX), X0
+	MULPS	X4, X0
+	MOVUPS	(BX), X1
+	MULPS	X5, X1
+	ADDPS	X1, X0
+	MOVUPS	(CX), X1
+	MULPS	X6, X1
+	ADDPS	X1, X0
+	MOVUPS	X0, (DI)
+	ADDQ	$16, AX
+	ADDQ	$16, BX
+	ADDQ	$16, CX
+	ADDQ	$16, DI
+	SUBQ	$4, DX
+	JNZ	loop
+done:
+	RET
+
+// 4 vectors at a time, n must be a multiple of 4
+//
+// X4-X15 hold the broadcasted elements of the matrix, the inputs are
+// loaded again for every output to get along with the registers. All of
+// them are read before the first store, so out may be v.
+TEXT ·transformSoA3SIMD4(SB),7,$0-64
+	MOVQ	m+0(FP), AX
+	MOVSS	0(AX), X4
+	MOVSS	4(AX), X5
+	MOVSS	8(AX), X6
+	MOVSS	16(AX), X7
+	MOVSS	20(AX), X8
+	MOVSS	24(AX), X9
+	MOVSS	32(AX), X10
+	MOVSS	36(AX), X11
+	MOVSS	40(AX), X12
+	MOVSS	48(AX), X13
+	MOVSS	52(AX), X14
+	MOVSS	56(AX), X15
+	SHUFPS	$0x00, X4, X4
+	SHUFPS	$0x00, X5, X5
+	SHUFPS	$0x00, X6, X6
+	SHUFPS	$0x00, X7, X7
+	SHUFPS	$0x00, X8, X8
+	SHUFPS	$0x00, X9, X9
+	SHUFPS	$0x00, X10, X10
+	SHUFPS	$0x00, X11, X11
+	SHUFPS	$0x00, X12, X12
+	SHUFPS	$0x00, X13, X13
+	SHUFPS	$0x00, X14, X14
+	SHUFPS	$0x00, X15, X15
+	MOVQ	x+8(FP), AX
+	MOVQ	y+16(FP), BX
+	MOVQ	z+24(FP), CX
+	MOVQ	ox+32(FP), SI
+	MOVQ	oy+40(FP), DI
+	MOVQ	oz+48(FP), R8
+	MOVQ	n+56(FP), DX
+	TESTQ	DX, DX
+	JLE	done
+loop:
+	MOVUPS	(AX), X1
+	MULPS	X4, X1
+	MOVUPS	(AX), X2
+	MULPS	X5, X2
+	MOVUPS	(AX), X3
+	MULPS	X6, X3
+	MOVUPS	(BX), X0
+	MULPS	X7, X0
+	ADDPS	X0, X1
+	MOVUPS	(BX), X0
+	MULPS	X8, X0
+	ADDPS	X0, X2
+	MOVUPS	(BX), X0
+	MULPS	X9, X0
+	ADDPS	X0, X3
+	MOVUPS	(CX), X0
+	MULPS	X10, X0
+	ADDPS	X0, X1
+	MOVUPS	(CX), X0
+	MULPS	X11, X0
+	ADDPS	X0, X2
+	MOVUPS	(CX), X0
+	MULPS	X12, X0
+	ADDPS	X0, X3
+	ADDPS	X13, X1
+	ADDPS	X14, X2
+	ADDPS	X15, X3
+	MOVUPS	X1, (SI)
+	MOVUPS	X2, (DI)
+	MOVUPS	X3, (R8)
+	ADDQ	$16, AX
+	ADDQ	$16, BX
+	ADDQ	$16, CX
+	ADDQ	$16, SI
+	ADDQ	$16, DI
+	ADDQ	$16, R8
+	SUBQ	$4, DX
+	JNZ	loop
+done:
+	RET
+
+TEXT ·crossSoA3SIMD4(SB),7,$0-80
+	MOVQ	ax+0(FP), AX
+	MOVQ	ay+8(FP), BX
+	MOVQ	az+16(FP), CX
+	MOVQ	bx+24(FP), DX
+	MOVQ	by+32(FP), SI
+	MOVQ	bz+40(FP), DI
+	MOVQ	ox+48(FP), R8
+	MOVQ	oy+56(FP), R9
+	MOVQ	oz+64(FP), R10
+	MOVQ	n+72(FP), R11
+	TESTQ	R11, R11
+	JLE	done
+loop:
+	MOVUPS	(AX), X0
+	MOVUPS	(BX), X1
+	MOVUPS	(CX), X2
+	MOVUPS	(DX), X3
+	MOVUPS	(SI), X4
+	MOVUPS	(DI), X5
+
+	MOVAPS	X1, X6
+	MULPS	X5, X6
+	MOVAPS	X2, X7
+	MULPS	X4, X7
+	SUBPS	X7, X6
+	MOVUPS	X6, (R8)
+
+	MOVAPS	X2, X6
+	MULPS	X3, X6
+	MOVAPS	X0, X7
+	MULPS	X5, X7
+	SUBPS	X7, X6
+	MOVUPS	X6, (R9)
+
+	MOVAPS	X0, X6
+	MULPS	X4, X6
+	MOVAPS	X1, X7
+	MULPS	X3, X7
+	SUBPS	X7, X6
+	MOVUPS	X6, (R10)
+
+	ADDQ	$16, AX
+	ADDQ	$16, BX
+	ADDQ	$16, CX
+	ADDQ	$16, DX
+	ADDQ	$16, SI
+	ADDQ	$16, DI
+	ADDQ	$16, R8
+	ADDQ	$16, R9
+	ADDQ	$16, R10
+	SUBQ	$4, R11
+	JNZ	loop
+done:
+	RET
+
+// the squares are summed up like in Go (xx + yy) + zz
+TEXT ·lengthSoA3SIMD4(SB),7,$0-40
+	MOVQ	x+0(FP), AX
+	MOVQ	y+8(FP), BX
+	MOVQ	z+16(FP), CX
+	MOVQ	out+24(FP), DI
+	MOVQ	n+32(FP), DX
+	TESTQ	DX, DX
+	JLE	done
+loop:
+	MOVUPS	(AX), X0
+	MULPS	X0, X0
+	MOVUPS	(BX), X1
+	MULPS	X1, X1
+	ADDPS	X1, X0
+	MOVUPS	(CX), X1
+	MULPS	X1, X1
+	ADDPS	X1, X0
+	SQRTPS	X0, X0
+	MOVUPS	X0, (DI)
+	ADDQ	$16, AX
+	ADDQ	$16, BX
+	ADDQ	$16, CX
+	ADDQ	$16, DI
+	SUBQ	$4, DX
+	JNZ	loop
+done:
+	RET
+
+TEXT ·boundsBoxesSIMD4(SB),7,$0-24
+	MOVQ	boxes+0(FP), AX
+	MOVQ	n+8(FP), DX
+	MOVQ	bb+16(FP), CX
+	MOVUPS	(CX), X0
+	MOVUPS	16(CX), X1
+	TESTQ	DX, DX
+	JLE	done
+loop:
+	MOVUPS	(AX), X2
+	MOVUPS	16(AX), X3
+	MINPS	X2, X0
+	MAXPS	X3, X1
+	ADDQ	$32, AX
+	DECQ	DX
+	JNZ	loop
+done:
+	MOVUPS	X0, (CX)
+	MOVUPS	X1, 16(CX)
+	RET
+
+// 2 times 4 boxes, same as orthoBoxIntersect() for every lane
+//
+// X8-X10 hold the origin, X11-X13 the inverse direction and X14/X15 -/+inf
+TEXT ·orthoBoxIntersect8SIMD4(SB),7,$0-40
+	MOVQ	b+0(FP), AX
+	MOVQ	p0+8(FP), BX
+	MOVQ	inv+16(FP), CX
+	MOVQ	tmin+24(FP), DI
+	MOVQ	tmax+32(FP), SI
+	MOVSS	(BX), X8
+	MOVSS	4(BX), X9
+	MOVSS	8(BX), X10
+	MOVSS	(CX), X11
+	MOVSS	4(CX), X12
+	MOVSS	8(CX), X13
+	SHUFPS	$0x00, X8, X8
+	SHUFPS	$0x00, X9, X9
+	SHUFPS	$0x00, X10, X10
+	SHUFPS	$0x00, X11, X11
+	SHUFPS	$0x00, X12, X12
+	SHUFPS	$0x00, X13, X13
+	MOVL	$0xff800000, DX
+	MOVQ	DX, X14
+	SHUFPS	$0x00, X14, X14
+	MOVL	$0x7f800000, DX
+	MOVQ	DX, X15
+	SHUFPS	$0x00, X15, X15
+	MOVQ	$2, DX
+loop:
+	MOVAPS	X14, X0
+	MOVAPS	X15, X1
+	MOVUPS	0(AX), X2
+	SUBPS	X8, X2
+	MULPS	X11, X2
+	MOVUPS	96(AX), X3
+	SUBPS	X8, X3
+	MULPS	X11, X3
+	MOVAPS	X2, X4
+	CMPPS	X3, X4, $7
+	MOVAPS	X2, X5
+	MINPS	X3, X5
+	MAXPS	X3, X2
+	ANDPS	X4, X5
+	ANDPS	X4, X2
+	MOVAPS	X4, X6
+	ANDNPS	X14, X6
+	ORPS	X6, X5
+	MOVAPS	X4, X6
+	ANDNPS	X15, X6
+	ORPS	X6, X2
+	MAXPS	X5, X0
+	MINPS	X2, X1
+	MOVUPS	32(AX), X2
+	SUBPS	X9, X2
+	MULPS	X12, X2
+	MOVUPS	128(AX), X3
+	SUBPS	X9, X3
+	MULPS	X12, X3
+	MOVAPS	X2, X4
+	CMPPS	X3, X4, $7
+	MOVAPS	X2, X5
+	MINPS	X3, X5
+	MAXPS	X3, X2
+	ANDPS	X4, X5
+	ANDPS	X4, X2
+	MOVAPS	X4, X6
+	ANDNPS	X14, X6
+	ORPS	X6, X5
+	MOVAPS	X4, X6
+	ANDNPS	X15, X6
+	ORPS	X6, X2
+	MAXPS	X5, X0
+	MINPS	X2, X1
+	MOVUPS	64(AX), X2
+	SUBPS	X10, X2
+	MULPS	X13, X2
+	MOVUPS	160(AX), X3
+	SUBPS	X10, X3
+	MULPS	X13, X3
+	MOVAPS	X2, X4
+	CMPPS	X3, X4, $7
+	MOVAPS	X2, X5
+	MINPS	X3, X5
+	MAXPS	X3, X2
+	ANDPS	X4, X5
+	ANDPS	X4, X2
+	MOVAPS	X4, X6
+	ANDNPS	X14, X6
+	ORPS	X6, X5
+	MOVAPS	X4, X6
+	ANDNPS	X15, X6
+	ORPS	X6, X2
+	MAXPS	X5, X0
+	MINPS	X2, X1
+	MOVUPS	X0, (DI)
+	MOVUPS	X1, (SI)
+	ADDQ	$16, AX
+	ADDQ	$16, DI
+	ADDQ	$16, SI
+	DECQ	DX
+	JNZ	loop
+	RET
@@ -0,0 +1,206 @@
+//go:build !purego
+
+// AVX2 kernels for batch.go, 8 floats at a time
+//
+// Only the length uses FMA. For the other kernels the single rounding
+// would change the results noticeably when terms cancel out, so they
+// stick to the order of operations of the Go versions.
+
+TEXT ·dotSoA3SIMD8(SB),7,$0-48
+	MOVQ	x+0(FP), AX
+	MOVQ	y+8(FP), BX
+	MOVQ	z+16(FP), CX
+	MOVQ	c+24(FP), SI
+	MOVQ	out+32(FP), DI
+	MOVQ	n+40(FP), DX
+	VBROADCASTSS	(SI), Y4
+	VBROADCASTSS	4(SI), Y5
+	VBROADCASTSS	8(SI), Y6
+	TESTQ	DX, DX
+	JLE	done
+loop:
+	VMULPS	(AX), Y4, Y0
+	VMULPS	(BX), Y5, Y1
+	VADDPS	Y1, Y0, Y0
+	VMULPS	(CX), Y6, Y1
+	VADDPS	Y1, Y0, Y0
+	VMOVUPS	Y0, (DI)
+	ADDQ	$32, AX
+	ADDQ	$32, BX
+	ADDQ	$32, CX
+	ADDQ	$32, DI
+	SUBQ	$8, DX
+	JNZ	loop
+done:
+	VZEROUPPER
+	RET
+
+TEXT ·crossSoA3SIMD8(SB),7,$0-80
+	MOVQ	ax+0(FP), AX
+	MOVQ	ay+8(FP), BX
+	MOVQ	az+16(FP), CX
+	MOVQ	bx+24(FP), DX
+	MOVQ	by+32(FP), SI
+	MOVQ	bz+40(FP), DI
+	MOVQ	ox+48(FP), R8
+	MOVQ	oy+56(FP), R9
+	MOVQ	oz+64(FP), R10
+	MOVQ	n+72(FP), R11
+	TESTQ	R11, R11
+	JLE	done
+loop:
+	VMOVUPS	(AX), Y0
+	VMOVUPS	(BX), Y1
+	VMOVUPS	(CX), Y2
+	VMOVUPS	(DX), Y3
+	VMOVUPS	(SI), Y4
+	VMOVUPS	(DI), Y5
+
+	VMULPS	Y5, Y1, Y6
+	VMULPS	Y4, Y2, Y7
+	VSUBPS	Y7, Y6, Y6
+	VMOVUPS	Y6, (R8)
+
+	VMULPS	Y3, Y2, Y6
+	VMULPS	Y5, Y0, Y7
+	VSUBPS	Y7, Y6, Y6
+	VMOVUPS	Y6, (R9)
+
+	VMULPS	Y4, Y0, Y6
+	VMULPS	Y3, Y1, Y7
+	VSUBPS	Y7, Y6, Y6
+	VMOVUPS	Y6, (R10)
+
+	ADDQ	$32, AX
+	ADDQ	$32, BX
+	ADDQ	$32, CX
+	ADDQ	$32, DX
+	ADDQ	$32, SI
+	ADDQ	$32, DI
+	ADDQ	$32, R8
+	ADDQ	$32, R9
+	ADDQ	$32, R10
+	SUBQ	$8, R11
+	JNZ	loop
+done:
+	VZEROUPPER
+	RET
+
+// xx + yy + zz with FMA, only positive terms so the results are within
+// AlmostEqual() of the Go version
+TEXT ·lengthSoA3SIMD8(SB),7,$0-40
+	MOVQ	x+0(FP), AX
+	MOVQ	y+8(FP), BX
+	MOVQ	z+16(FP), CX
+	MOVQ	out+24(FP), DI
+	MOVQ	n+32(FP), DX
+	TESTQ	DX, DX
+	JLE	done
+loop:
+	VMOVUPS	(AX), Y0
+	VMOVUPS	(BX), Y1
+	VMOVUPS	(CX), Y2
+	VMULPS	Y0, Y0, Y0
+	VFMADD231PS	Y1, Y1, Y0
+	VFMADD231PS	Y2, Y2, Y0
+	VSQRTPS	Y0, Y0
+	VMOVUPS	Y0, (DI)
+	ADDQ	$32, AX
+	ADDQ	$32, BX
+	ADDQ	$32, CX
+	ADDQ	$32, DI
+	SUBQ	$8, DX
+	JNZ	loop
+done:
+	VZEROUPPER
+	RET
+
+// an OrthoBox fills a YMM register, P0 is taken from the minimum and P1
+// from the maximum
+TEXT ·boundsBoxesSIMD8(SB),7,$0-24
+	MOVQ	boxes+0(FP), AX
+	MOVQ	n+8(FP), DX
+	MOVQ	bb+16(FP), CX
+	VMOVUPS	(CX), Y0
+	VMOVAPS	Y0, Y1
+	TESTQ	DX, DX
+	JLE	done
+loop:
+	VMOVUPS	(AX), Y2
+	VMINPS	Y2, Y0, Y0
+	VMAXPS	Y2, Y1, Y1
+	ADDQ	$32, AX
+	DECQ	DX
+	JNZ	loop
+done:
+	VBLENDPS	$0xf0, Y1, Y0, Y0
+	VMOVUPS	Y0, (CX)
+	VZEROUPPER
+	RET
+
+// same as orthoBoxIntersect() for every lane
+//
+// Y8-Y10 hold the origin, Y11-Y13 the inverse direction and Y14/Y15 -/+inf
+TEXT ·orthoBoxIntersect8SIMD8(SB),7,$0-40
+	MOVQ	b+0(FP), AX
+	MOVQ	p0+8(FP), BX
+	MOVQ	inv+16(FP), CX
+	MOVQ	tmin+24(FP), DI
+	MOVQ	tmax+32(FP), SI
+	VBROADCASTSS	(BX), Y8
+	VBROADCASTSS	4(BX), Y9
+	VBROADCASTSS	8(BX), Y10
+	VBROADCASTSS	(CX), Y11
+	VBROADCASTSS	4(CX), Y12
+	VBROADCASTSS	8(CX), Y13
+	MOVL	$0xff800000, DX
+	VMOVD	DX, X14
+	VBROADCASTSS	X14, Y14
+	MOVL	$0x7f800000, DX
+	VMOVD	DX, X15
+	VBROADCASTSS	X15, Y15
+	VMOVAPS	Y14, Y0
+	VMOVAPS	Y15, Y1
+	VMOVUPS	0(AX), Y2
+	VSUBPS	Y8, Y2, Y2
+	VMULPS	Y11, Y2, Y2
+	VMOVUPS	96(AX), Y3
+	VSUBPS	Y8, Y3, Y3
+	VMULPS	Y11, Y3, Y3
+	VCMPPS	$7, Y3, Y2, Y4
+	VMINPS	Y3, Y2, Y5
+	VMAXPS	Y3, Y2, Y6
+	VBLENDVPS	Y4, Y5, Y14, Y5
+	VBLENDVPS	Y4, Y6, Y15, Y6
+	VMAXPS	Y5, Y0, Y0
+	VMINPS	Y6, Y1, Y1
+	VMOVUPS	32(AX), Y2
+	VSUBPS	Y9, Y2, Y2
+	VMULPS	Y12, Y2, Y2
+	VMOVUPS	128(AX), Y3
+	VSUBPS	Y9, Y3, Y3
+	VMULPS	Y12, Y3, Y3
+	VCMPPS	$7, Y3, Y2, Y4
+	VMINPS	Y3, Y2, Y5
+	VMAXPS	Y3, Y2, Y6
+	VBLENDVPS	Y4, Y5, Y14, Y5
+	VBLENDVPS	Y4, Y6, Y15, Y6
+	VMAXPS	Y5, Y0, Y0
+	VMINPS	Y6, Y1, Y1
+	VMOVUPS	64(AX), Y2
+	VSUBPS	Y10, Y2, Y2
+	VMULPS	Y13, Y2, Y2
+	VMOVUPS	160(AX), Y3
+	VSUBPS	Y10, Y3, Y3
+	VMULPS	Y13, Y3, Y3
+	VCMPPS	$7, Y3, Y2, Y4
+	VMINPS	Y3, Y2, Y5
+	VMAXPS	Y3, Y2, Y6
+	VBLENDVPS	Y4, Y5, Y14, Y5
+	VBLENDVPS	Y4, Y6, Y15, Y6
+	VMAXPS	Y5, Y0, Y0
+	VMINPS	Y6, Y1, Y1
+	VMOVUPS	Y0, (DI)
+	VMOVUPS	Y1, (SI)
+	VZEROUPPER
+	RET
@@ -0,0 +1,83 @@
+//go:build !amd64 || purego
+
+package vec32
+
+import (
+	"unsafe"
+)
+
+// Go versions of the kernels of batch.go, used on platforms without
+// assembly or if built with the purego tag
+
+func addSlice3SIMD4(a, b, out *Vec3, n int) {
+	addSlice3(unsafe.Slice(a, n), unsafe.Slice(b, n), unsafe.Slice(out, n))
+}
+
+func scaleSlice3SIMD4(v *Vec3, s float32, out *Vec3, n int) {
+	scaleSlice3(unsafe.Slice(v, n), s, unsafe.Slice(out, n))
+}
+
+func transformSlice3SIMD4(m *Mat4, v, out *Vec3, n int) {
+	transformSlice3(m, unsafe.Slice(v, n), unsafe.Slice(out, n))
+}
+
+func normalizeSlice3SIMD4(v, out *Vec3, n int) {
+	normalizeSlice3(unsafe.Slice(v, n), unsafe.Slice(out, n))
+}
+
+func boundsSlice3SIMD4(v *Vec3, n int, bb *OrthoBox) {
+	boundsSlice3(unsafe.Slice(v, n), bb)
+}
+
+func dotSoA3SIMD4(x, y, z *float32, c *Vec3, out *float32, n int) {
+	v := Vec3SoA{unsafe.Slice(x, n), unsafe.Slice(y, n), unsafe.Slice(z, n)}
+	dotSoA3(&v, c, unsafe.Slice(out, n), 0, n)
+}
+
+func transformSoA3SIMD4(m *Mat4, x, y, z, ox, oy, oz *float32, n int) {
+	v := Vec3SoA{unsafe.Slice(x, n), unsafe.Slice(y, n), unsafe.Slice(z, n)}
+	out := Vec3SoA{unsafe.Slice(ox, n), unsafe.Slice(oy, n), unsafe.Slice(oz, n)}
+	transformSoA3(m, &v, &out, 0, n)
+}
+
+func crossSoA3SIMD4(ax, ay, az, bx, by, bz, ox, oy, oz *float32, n int) {
+	a := Vec3SoA{unsafe.Slice(ax, n), unsafe.Slice(ay, n), unsafe.Slice(az, n)}
+	b := Vec3SoA{unsafe.Slice(bx, n), unsafe.Slice(by, n), unsafe.Slice(bz, n)}
+	out := Vec3SoA{unsafe.Slice(ox, n), unsafe.Slice(oy, n), unsafe.Slice(oz, n)}
+	crossSoA3(&a, &b, &out, 0, n)
+}
+
+func lengthSoA3SIMD4(x, y, z, out *float32, n int) {
+	v := Vec3SoA{unsafe.Slice(x, n), unsafe.Slice(y, n), unsafe.Slice(z, n)}
+	lengthSoA3(&v, unsafe.Slice(out, n), 0, n)
+}
+
+func boundsBoxesSIMD4(boxes *OrthoBox, n int, bb *OrthoBox) {
+	boundsBoxes(unsafe.Slice(boxes, n), bb)
+}
+
+func orthoBoxIntersect8SIMD4(b *OrthoBox8, p0, inv *Vec3, tmin, tmax *[8]float32) {
+	orthoBoxIntersect8(b, p0, inv, tmin, tmax)
+}
+
+// the 8-wide kernels, SIMDLevel() never allows them either
+
+func dotSoA3SIMD8(x, y, z *float32, c *Vec3, out *float32, n int) {
+	dotSoA3SIMD4(x, y, z, c, out, n)
+}
+
+func crossSoA3SIMD8(ax, ay, az, bx, by, bz, ox, oy, oz *float32, n int) {
+	crossSoA3SIMD4(ax, ay, az, bx, by, bz, ox, oy, oz, n)
+}
+
+func lengthSoA3SIMD8(x, y, z, out *float32, n int) {
+	lengthSoA3SIMD4(x, y, z, out, n)
+}
+
+func boundsBoxesSIMD8(boxes *OrthoBox, n int, bb *OrthoBox) {
+	boundsBoxesSIMD4(boxes, n, bb)
+}
+
+func orthoBoxIntersect8SIMD8(b *OrthoBox8, p0, inv *Vec3, tmin, tmax *[8]float32) {
+	orthoBoxIntersect8(b, p0, inv, tmin, tmax)
+}
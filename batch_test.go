@@ -0,0 +1,282 @@
+package vec32
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func randomVec3s(rnd *rand.Rand, n int) []Vec3 {
+	v := make([]Vec3, n)
+	for i := range v {
+		v[i] = NewVec3(rnd.Float32()*20-10, rnd.Float32()*20-10, rnd.Float32()*20-10)
+	}
+	return v
+}
+
+func testVec3s(t *testing.T, name string, exp, cur []Vec3) {
+	for i := range exp {
+		if !AlmostEqual3(&exp[i], &cur[i]) || cur[i].pad != 0 {
+			t.Errorf("%s is wrong at %d - expected %s got %s (pad %g)",
+				name, i, exp[i].String(), cur[i].String(), cur[i].pad)
+			return
+		}
+	}
+}
+
+func testFloats(t *testing.T, name string, exp, cur []float32) {
+	for i := range exp {
+		if !AlmostEqual(exp[i], cur[i]) {
+			t.Errorf("%s is wrong at %d - expected %g got %g", name, i, exp[i], cur[i])
+			return
+		}
+	}
+}
+
+func testBounds(t *testing.T, name string, exp, cur *OrthoBox) {
+	if !exp.P0.IsEqual(&cur.P0) || !exp.P1.IsEqual(&cur.P1) {
+		t.Errorf("%s is wrong - expected %s got %s", name, exp.String(), cur.String())
+	}
+}
+
+func TestBatchSlice3(t *testing.T) {
+	rnd := rand.New(rand.NewSource(23))
+	m := NewMat4Rotate(&Vec3{X: 1, Y: 2, Z: 3}, 0.7)
+	m[3][0], m[3][1], m[3][2] = 1, -2, 3
+	c := NewVec3(0.5, -1, 2)
+	for _, n := range []int{0, 1, 2, 3, 4, 5, 7, 8, 9, 100} {
+		a := randomVec3s(rnd, n)
+		b := randomVec3s(rnd, n)
+		exp := make([]Vec3, n)
+		cur := make([]Vec3, n)
+
+		addSlice3(a, b, exp)
+		AddSlice3(a, b, cur)
+		testVec3s(t, "AddSlice3()", exp, cur)
+		scaleSlice3(a, 1.5, exp)
+		ScaleSlice3(a, 1.5, cur)
+		testVec3s(t, "ScaleSlice3()", exp, cur)
+		transformSlice3(&m, a, exp)
+		TransformSlice3(&m, a, cur)
+		testVec3s(t, "TransformSlice3()", exp, cur)
+		normalizeSlice3(a, exp)
+		NormalizeSlice3(a, cur)
+		testVec3s(t, "NormalizeSlice3()", exp, cur)
+
+		expDot := make([]float32, n)
+		curDot := make([]float32, n)
+		dotSlice3(a, &c, expDot)
+		DotSlice3(a, &c, curDot)
+		testFloats(t, "DotSlice3()", expDot, curDot)
+
+		expBB, curBB := ORTHO_EMPTY, ORTHO_EMPTY
+		boundsSlice3(a, &expBB)
+		BoundsSlice3(a, &curBB)
+		testBounds(t, "BoundsSlice3()", &expBB, &curBB)
+
+		// in place
+		copy(cur, a)
+		AddSlice3(cur, b, cur)
+		addSlice3(a, b, exp)
+		testVec3s(t, "AddSlice3() in place", exp, cur)
+	}
+}
+
+func TestBatchSlice3Inf(t *testing.T) {
+	a := []Vec3{NewVec3(1, 0, -1)}
+	out := make([]Vec3, 1)
+	ScaleSlice3(a, Inf(1), out)
+	if out[0].pad != 0 {
+		t.Errorf("pad must stay zero, got %g", out[0].pad)
+	}
+}
+
+func TestBatchShortSlice(t *testing.T) {
+	defer func() {
+		if r := recover(); r == nil {
+			t.Errorf("short output should panic")
+		}
+	}()
+	AddSlice3(make([]Vec3, 4), make([]Vec3, 4), make([]Vec3, 3))
+}
+
+func TestVec3SoA(t *testing.T) {
+	rnd := rand.New(rand.NewSource(29))
+	v := randomVec3s(rnd, 5)
+	s := NewVec3SoAFromSlice(v)
+	testFloat(t, "Len()", 5, float32(s.Len()))
+	testVec3s(t, "AppendTo()", v, s.AppendTo(nil))
+	p := NewVec3(1, 2, 3)
+	s.Set(2, &p)
+	testVec3(t, "At()", p, s.At(2))
+}
+
+func TestBatchSoA3(t *testing.T) {
+	prev := SIMDLevel()
+	defer SetSIMDLevel(prev)
+	rnd := rand.New(rand.NewSource(31))
+	m := NewMat4Rotate(&Vec3{X: -1, Y: 2, Z: 1}, 1.3)
+	m[3][0], m[3][1], m[3][2] = 4, 5, -6
+	c := NewVec3(-2, 0.5, 1)
+	for _, n := range []int{0, 1, 3, 4, 5, 8, 11, 100} {
+		a := randomVec3s(rnd, n)
+		b := randomVec3s(rnd, n)
+		sa := NewVec3SoAFromSlice(a)
+		sb := NewVec3SoAFromSlice(b)
+		out := NewVec3SoA(n)
+		exp := make([]Vec3, n)
+
+		AddSoA3(sa, sb, out)
+		addSlice3(a, b, exp)
+		testVec3s(t, "AddSoA3()", exp, out.AppendTo(nil))
+		ScaleSoA3(sa, -3, out)
+		scaleSlice3(a, -3, exp)
+		testVec3s(t, "ScaleSoA3()", exp, out.AppendTo(nil))
+		TransformSoA3(&m, sa, out)
+		transformSlice3(&m, a, exp)
+		testVec3s(t, "TransformSoA3()", exp, out.AppendTo(nil))
+		NormalizeSoA3(sa, out)
+		normalizeSlice3(a, exp)
+		testVec3s(t, "NormalizeSoA3()", exp, out.AppendTo(nil))
+
+		expDot := make([]float32, n)
+		curDot := make([]float32, n)
+		dotSlice3(a, &c, expDot)
+		DotSoA3(sa, &c, curDot)
+		testFloats(t, "DotSoA3()", expDot, curDot)
+
+		expBB, curBB := ORTHO_EMPTY, ORTHO_EMPTY
+		boundsSlice3(a, &expBB)
+		BoundsSoA3(sa, &curBB)
+		testBounds(t, "BoundsSoA3()", &expBB, &curBB)
+
+		// outputs may be the inputs
+		for _, l := range simdTestLevels {
+			if l.level > simdSupported {
+				continue
+			}
+			SetSIMDLevel(l.level)
+			sa, sb := NewVec3SoAFromSlice(a), NewVec3SoAFromSlice(b)
+			for i := range a {
+				exp[i] = a[i].Crossed(b[i])
+			}
+			CrossSoA3(sa, sb, sb)
+			testVec3s(t, "CrossSoA3() into b "+l.name, exp, sb.AppendTo(nil))
+			for i := range a {
+				exp[i] = a[i].Crossed(exp[i])
+			}
+			CrossSoA3(sa, sb, sa)
+			testVec3s(t, "CrossSoA3() into a "+l.name, exp, sa.AppendTo(nil))
+			transformSlice3(&m, sb.AppendTo(nil), exp)
+			TransformSoA3(&m, sb, sb)
+			testVec3s(t, "TransformSoA3() in place "+l.name, exp, sb.AppendTo(nil))
+		}
+		SetSIMDLevel(prev)
+	}
+}
+
+const benchBatchSize = 4096
+
+func benchVec3s() ([]Vec3, []Vec3) {
+	rnd := rand.New(rand.NewSource(37))
+	return randomVec3s(rnd, benchBatchSize), make([]Vec3, benchBatchSize)
+}
+
+func BenchmarkBatchAddSlice3(b *testing.B) {
+	v, out := benchVec3s()
+	b.SetBytes(benchBatchSize * 16)
+	for i := 0; i < b.N; i++ {
+		AddSlice3(v, v, out)
+	}
+}
+
+func BenchmarkBatchAddSlice3Generic(b *testing.B) {
+	v, out := benchVec3s()
+	b.SetBytes(benchBatchSize * 16)
+	for i := 0; i < b.N; i++ {
+		addSlice3(v, v, out)
+	}
+}
+
+func BenchmarkBatchDotSlice3(b *testing.B) {
+	v, _ := benchVec3s()
+	out := make([]float32, benchBatchSize)
+	c := NewVec3(1, 2, 3)
+	b.SetBytes(benchBatchSize * 16)
+	for i := 0; i < b.N; i++ {
+		DotSlice3(v, &c, out)
+	}
+}
+
+func BenchmarkBatchDotSoA3(b *testing.B) {
+	v, _ := benchVec3s()
+	s := NewVec3SoAFromSlice(v)
+	out := make([]float32, benchBatchSize)
+	c := NewVec3(1, 2, 3)
+	b.SetBytes(benchBatchSize * 12)
+	for i := 0; i < b.N; i++ {
+		DotSoA3(s, &c, out)
+	}
+}
+
+func BenchmarkBatchTransformSlice3(b *testing.B) {
+	v, out := benchVec3s()
+	m := NewMat4Rotate(&Vec3{X: 1, Y: 2, Z: 3}, 0.5)
+	b.SetBytes(benchBatchSize * 16)
+	for i := 0; i < b.N; i++ {
+		TransformSlice3(&m, v, out)
+	}
+}
+
+func BenchmarkBatchTransformSlice3Generic(b *testing.B) {
+	v, out := benchVec3s()
+	m := NewMat4Rotate(&Vec3{X: 1, Y: 2, Z: 3}, 0.5)
+	b.SetBytes(benchBatchSize * 16)
+	for i := 0; i < b.N; i++ {
+		transformSlice3(&m, v, out)
+	}
+}
+
+func BenchmarkBatchTransformSoA3(b *testing.B) {
+	v, _ := benchVec3s()
+	s := NewVec3SoAFromSlice(v)
+	out := NewVec3SoA(benchBatchSize)
+	m := NewMat4Rotate(&Vec3{X: 1, Y: 2, Z: 3}, 0.5)
+	b.SetBytes(benchBatchSize * 12)
+	for i := 0; i < b.N; i++ {
+		TransformSoA3(&m, s, out)
+	}
+}
+
+func BenchmarkBatchNormalizeSlice3(b *testing.B) {
+	v, out := benchVec3s()
+	b.SetBytes(benchBatchSize * 16)
+	for i := 0; i < b.N; i++ {
+		NormalizeSlice3(v, out)
+	}
+}
+
+func BenchmarkBatchNormalizeSlice3Generic(b *testing.B) {
+	v, out := benchVec3s()
+	b.SetBytes(benchBatchSize * 16)
+	for i := 0; i < b.N; i++ {
+		normalizeSlice3(v, out)
+	}
+}
+
+func BenchmarkBatchBoundsSlice3(b *testing.B) {
+	v, _ := benchVec3s()
+	b.SetBytes(benchBatchSize * 16)
+	for i := 0; i < b.N; i++ {
+		bb := ORTHO_EMPTY
+		BoundsSlice3(v, &bb)
+	}
+}
+
+func BenchmarkBatchBoundsSlice3Generic(b *testing.B) {
+	v, _ := benchVec3s()
+	b.SetBytes(benchBatchSize * 16)
+	for i := 0; i < b.N; i++ {
+		bb := ORTHO_EMPTY
+		boundsSlice3(v, &bb)
+	}
+}
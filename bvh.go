@@ -3,6 +3,7 @@ package vec32
 // The root structure to pass around
 type BVHTree struct {
 	root *bvhNode
+	m    *Mesh
 	Opt  *BVHBuildOptions
 }
 
@@ -41,6 +42,7 @@ func NewBVHTree(m *Mesh, opt *BVHBuildOptions) (*BVHTree, error) {
 		opt = NewBVHDefaultOptions()
 	}
 	bvhb := bvhBuilder{m: m}
+	bvhb.bvh.m = m
 	bvhb.bvh.Opt = opt
 	var e error
 	if e = bvhb.createBuildNodes(); e != nil {
@@ -59,13 +61,18 @@ func NewBVHDefaultOptions() *BVHBuildOptions {
 }
 
 func (bvhb *bvhBuilder) createBuildNodes() error {
-	bvhb.nodes = make([]bvhBuildNode, len(bvhb.m.Tris))
+	if err := bvhb.m.checkTris(); err != nil {
+		return err
+	}
+	n := bvhb.m.TriCount()
+	bvhb.nodes = make([]bvhBuildNode, n)
 	bvhb.bvh.root = &bvhNode{bb: ORTHO_EMPTY}
-	bvhb.bvh.root.tris = make([]int, len(bvhb.m.Tris))
-	for i := 0; i < len(bvhb.m.Tris); i++ {
+	bvhb.bvh.root.tris = make([]int, n)
+	for i := 0; i < n; i++ {
 		bvhb.bvh.root.tris[i] = i
 	}
-	for i, tri := range bvhb.m.Tris {
+	for i := 0; i < n; i++ {
+		tri := bvhb.m.Tri(i)
 		tri.OrthoBox(&bvhb.nodes[i].bb)
 		tri.Center(&bvhb.nodes[i].p)
 		bvhb.bvh.root.bb.Add(&bvhb.nodes[i].bb)
@@ -99,7 +106,7 @@ func (bvhb *bvhBuilder) getSplit(n *bvhNode) {
 	}
 	if DEBUG_LOG > 2 {
 		for t := 0; t < len(n.tris); t++ {
-			tri := bvhb.m.Tris[n.tris[t]]
+			tri := bvhb.m.Tri(n.tris[t])
 			var bb OrthoBox
 			tri.OrthoBox(&bb)
 			Trace.Printf("tri %d: %s P0: %s", t, bb.String(),
@@ -110,7 +117,7 @@ func (bvhb *bvhBuilder) getSplit(n *bvhNode) {
 	if len(n.tris) < bvhb.bvh.Opt.TrisPerNodeMin {
 		return
 	}
-	dist := n.bb.P1.Sub(&n.bb.P0)
+	dist := n.bb.P1.Minus(n.bb.P0)
 	dimVec := getDimVec(&n.bb)
 	if dimVec.LengthSq() == 0 {
 		return
@@ -236,7 +243,7 @@ func (bvhb *bvhBuilder) getSplit(n *bvhNode) {
 }
 
 func getDimVec(bb *OrthoBox) Vec3 {
-	dist := bb.P1.Sub(&bb.P0)
+	dist := bb.P1.Minus(bb.P0)
 	if dist.X >= dist.Y && dist.X >= dist.Z && dist.X > 10*EPS {
 		return NewVec3(1, 0, 0)
 	} else if dist.Y >= dist.X && dist.Y >= dist.Z && dist.Y > 10*EPS {
@@ -253,6 +260,11 @@ func (bvh *BVHTree) OrthoBox() OrthoBox {
 	return bvh.root.bb
 }
 
+// Get the mesh the tree was built for
+func (bvh *BVHTree) Mesh() *Mesh {
+	return bvh.m
+}
+
 // get the cost of a build
 //
 // less is better by the way
@@ -266,3 +278,135 @@ func (n *bvhNode) Cost() float32 {
 	}
 	return n.left.Cost() + n.right.Cost()
 }
+
+// initial depth of the traversal stack
+const BVH_STACK_SIZE = 64
+
+// Find the closest triangle hit by the ray
+//
+// returns the index of the triangle in the mesh and the distance along the
+// ray. If nothing is hit, -1 and inf are returned and hit is left untouched.
+func (bvh *BVHTree) Intersect(r *Ray, hit *Intersection) (triIdx int, t float32) {
+	var ri RayInv
+	var curr Intersection
+	var stackBuf [BVH_STACK_SIZE]*bvhNode
+	triIdx = -1
+	t = INF
+	if bvh.root == nil {
+		return
+	}
+	r.Inverse(&ri)
+	if _, ok := boxHit(&ri, &bvh.root.bb, t); !ok {
+		return
+	}
+	stack := append(stackBuf[:0], bvh.root)
+	for len(stack) > 0 {
+		n := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if n.left == nil {
+			for _, idx := range n.tris {
+				tri := bvh.m.Tri(idx)
+				tt := r.Intersect(&tri, &curr)
+				if tt < t {
+					t = tt
+					triIdx = idx
+					*hit = curr
+				}
+			}
+			continue
+		}
+		tLeft, okLeft := boxHit(&ri, &n.left.bb, t)
+		tRight, okRight := boxHit(&ri, &n.right.bb, t)
+		if okLeft && okRight {
+			// push the far child first, so the near one is visited next
+			if tLeft < tRight {
+				stack = append(stack, n.right, n.left)
+			} else {
+				stack = append(stack, n.left, n.right)
+			}
+		} else if okLeft {
+			stack = append(stack, n.left)
+		} else if okRight {
+			stack = append(stack, n.right)
+		}
+	}
+	return
+}
+
+// append the triangles whose boxes overlap bb to tris
+func (bvh *BVHTree) overlapping(bb *OrthoBox, tris []int) []int {
+	var stackBuf [BVH_STACK_SIZE]*bvhNode
+	if bvh.root == nil {
+		return tris
+	}
+	stack := append(stackBuf[:0], bvh.root)
+	for len(stack) > 0 {
+		n := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if !n.bb.Overlaps(bb) {
+			continue
+		}
+		if n.left == nil {
+			for _, idx := range n.tris {
+				var triBB OrthoBox
+				tri := bvh.m.Tri(idx)
+				tri.OrthoBox(&triBB)
+				if triBB.Overlaps(bb) {
+					tris = append(tris, idx)
+				}
+			}
+			continue
+		}
+		stack = append(stack, n.left, n.right)
+	}
+	return tris
+}
+
+// Check if anything is hit by the ray before tMax
+//
+// Other than Intersect() the traversal stops at the first hit, which makes
+// it the query of choice for shadow- and visibility-rays.
+func (bvh *BVHTree) Occluded(r *Ray, tMax float32) bool {
+	var ri RayInv
+	var curr Intersection
+	var stackBuf [BVH_STACK_SIZE]*bvhNode
+	if bvh.root == nil {
+		return false
+	}
+	r.Inverse(&ri)
+	if _, ok := boxHit(&ri, &bvh.root.bb, tMax); !ok {
+		return false
+	}
+	stack := append(stackBuf[:0], bvh.root)
+	for len(stack) > 0 {
+		n := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if n.left == nil {
+			for _, idx := range n.tris {
+				tri := bvh.m.Tri(idx)
+				if r.Intersect(&tri, &curr) < tMax {
+					return true
+				}
+			}
+			continue
+		}
+		if _, ok := boxHit(&ri, &n.left.bb, tMax); ok {
+			stack = append(stack, n.left)
+		}
+		if _, ok := boxHit(&ri, &n.right.bb, tMax); ok {
+			stack = append(stack, n.right)
+		}
+	}
+	return false
+}
+
+// check if the box is entered in front of the origin and before tMax
+//
+// returns the entry distance and if the box is hit
+func boxHit(ri *RayInv, bb *OrthoBox, tMax float32) (float32, bool) {
+	tmin, tmax := ri.IntersectOrthoBox(bb)
+	if tmin < 0 {
+		tmin = 0
+	}
+	return tmin, tmin <= tmax && tmin <= tMax
+}
@@ -1,7 +1,7 @@
 package vec32
 
 import (
-	"os"
+	"math/rand"
 	"testing"
 )
 
@@ -60,6 +60,109 @@ func TestSimpleSplit(t *testing.T) {
 	}
 }
 
+func TestBVHIntersect(t *testing.T) {
+	var cases = []string{
+		"paulbourke.net.sample1.ply",
+		"two_cubes.ply",
+		"two_cubes_2y.ply",
+		"people.sc.fsu.edu.helix.ply",
+	}
+	rnd := rand.New(rand.NewSource(42))
+	for i, file := range cases {
+		bvh, _ := buildBVH(t, i, file, nil)
+		if bvh == nil {
+			continue
+		}
+		m := bvh.Mesh()
+		for j := 0; j < 500; j++ {
+			r := randomRay(rnd, bvh.OrthoBox())
+			var hitExp, hitCur Intersection
+			idxExp, tExp := bruteForceIntersect(m, &r, &hitExp)
+			idxCur, tCur := bvh.Intersect(&r, &hitCur)
+			if tExp != tCur || (idxExp != idxCur && !AlmostEqual(tExp, tCur)) {
+				t.Errorf("tc %d ray %d: expected tri %d at %f, got tri %d at %f",
+					i, j, idxExp, tExp, idxCur, tCur)
+			} else if idxCur >= 0 && hitCur.T() != tCur {
+				t.Errorf("tc %d ray %d: intersection not filled", i, j)
+			}
+		}
+	}
+}
+
+func TestBVHIntersectMiss(t *testing.T) {
+	bvh, _ := buildBVH(t, 0, "two_cubes.ply", nil)
+	if bvh == nil {
+		return
+	}
+	var cases = []Ray{
+		{NewVec3(-1, -1, -1), NewVec3(-1, 0, 0)},
+		{NewVec3(0.5, 0.5, 5), NewVec3(0, 0, 1)},
+		{NewVec3(0.5, 5, 0.5), NewVec3(1, 0, 0)},
+	}
+	for i, r := range cases {
+		var hit Intersection
+		if idx, tt := bvh.Intersect(&r, &hit); idx != -1 || !IsInf(tt, 1) {
+			t.Errorf("tc %d: expected miss, got tri %d at %f", i, idx, tt)
+		}
+	}
+}
+
+func TestBVHOccluded(t *testing.T) {
+	var cases = []string{
+		"two_cubes.ply",
+		"people.sc.fsu.edu.helix.ply",
+	}
+	rnd := rand.New(rand.NewSource(7))
+	for i, file := range cases {
+		bvh, _ := buildBVH(t, i, file, nil)
+		if bvh == nil {
+			continue
+		}
+		for j := 0; j < 500; j++ {
+			r := randomRay(rnd, bvh.OrthoBox())
+			var hit Intersection
+			_, tHit := bvh.Intersect(&r, &hit)
+			tMax := 100 * rnd.Float32()
+			if exp := tHit < tMax; bvh.Occluded(&r, tMax) != exp {
+				t.Errorf("tc %d ray %d: expected occluded == %t (hit at %f, tMax %f)",
+					i, j, exp, tHit, tMax)
+			}
+		}
+	}
+}
+
+func BenchmarkBVHIntersect(b *testing.B) {
+	m, _ := getMesh(nil, 0, "people.sc.fsu.edu.helix.ply")
+	bvh, _ := NewBVHTree(m, nil)
+	rays := randomRays(1024, bvh.OrthoBox())
+	var hit Intersection
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		bvh.Intersect(&rays[i%len(rays)], &hit)
+	}
+}
+
+func BenchmarkBVHOccluded(b *testing.B) {
+	m, _ := getMesh(nil, 0, "people.sc.fsu.edu.helix.ply")
+	bvh, _ := NewBVHTree(m, nil)
+	rays := randomRays(1024, bvh.OrthoBox())
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		bvh.Occluded(&rays[i%len(rays)], INF)
+	}
+}
+
+func BenchmarkBVHIntersectBruteForce(b *testing.B) {
+	m, _ := getMesh(nil, 0, "people.sc.fsu.edu.helix.ply")
+	bvh, _ := NewBVHTree(m, nil)
+	rays := randomRays(1024, bvh.OrthoBox())
+	var hit Intersection
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		bruteForceIntersect(m, &rays[i%len(rays)], &hit)
+	}
+}
+
 func BenchmarkBVHBuilding(b *testing.B) {
 	m, _ := getMesh(nil, 0, "people.sc.fsu.edu.helix.ply")
 	b.ResetTimer()
@@ -89,16 +192,46 @@ func buildBVH(t *testing.T, i int, filename string, opts *BVHBuildOptions) (*BVH
 }
 
 func getMesh(t *testing.T, i int, fileName string) (*Mesh, error) {
-	var f *os.File
-	var err error
-	var m *Mesh
-	if f, err = os.Open("test/ply/" + fileName); err != nil {
-		t.Errorf("tc %d: could not open file %s: %s ", i, fileName, err.Error())
-		return nil, err
-	}
-	if m, err = ReadPLY(f); err != nil {
-		t.Errorf("tc %d: unexpected error on reading mesh: %s", i+1, err.Error())
+	m, err := LoadMesh("test/ply/" + fileName)
+	if err != nil {
+		t.Errorf("tc %d: unexpected error on loading mesh %s: %s", i+1, fileName, err.Error())
 		return nil, err
 	}
 	return m, nil
 }
+
+// shoot from somewhere around the box to somewhere inside the box
+func randomRay(rnd *rand.Rand, bb OrthoBox) Ray {
+	d := bb.P1.Sub(&bb.P0)
+	p0 := NewVec3(bb.P0.X+d.X*(3*rnd.Float32()-1),
+		bb.P0.Y+d.Y*(3*rnd.Float32()-1),
+		bb.P0.Z+d.Z*(3*rnd.Float32()-1))
+	p1 := NewVec3(bb.P0.X+d.X*rnd.Float32(),
+		bb.P0.Y+d.Y*rnd.Float32(),
+		bb.P0.Z+d.Z*rnd.Float32())
+	return *NewRay(&p0, &p1)
+}
+
+func randomRays(n int, bb OrthoBox) []Ray {
+	rnd := rand.New(rand.NewSource(1))
+	rays := make([]Ray, n)
+	for i := range rays {
+		rays[i] = randomRay(rnd, bb)
+	}
+	return rays
+}
+
+func bruteForceIntersect(m *Mesh, r *Ray, hit *Intersection) (int, float32) {
+	var curr Intersection
+	idx := -1
+	t := INF
+	for i, n := 0, m.TriCount(); i < n; i++ {
+		tri := m.Tri(i)
+		if tt := r.Intersect(&tri, &curr); tt < t {
+			t = tt
+			idx = i
+			*hit = curr
+		}
+	}
+	return idx, t
+}
@@ -0,0 +1,61 @@
+package vec32
+
+import (
+	"os"
+	"strings"
+)
+
+// SIMD levels used by the batch kernels
+const (
+	// plain Go
+	SIMD_GENERIC = iota
+	// 4-wide SSE
+	SIMD_SSE
+	// 8-wide AVX2, FMA where it does not change the results noticeably
+	SIMD_AVX2
+)
+
+// name of the environment variable to force a SIMD level ("generic", "sse"
+// or "avx2"), levels the CPU does not support are lowered
+const SIMD_ENV = "VEC32_SIMD"
+
+var simdNames = map[string]int{
+	"generic": SIMD_GENERIC,
+	"sse":     SIMD_SSE,
+	"avx2":    SIMD_AVX2,
+}
+
+// detected at startup
+var simdSupported int
+
+// in use by the batch kernels
+var simdLevel int
+
+// Get the SIMD level used by the batch kernels
+func SIMDLevel() int {
+	return simdLevel
+}
+
+// Force a SIMD level, returns the previous one
+//
+// Levels above the ones supported by the CPU are lowered. Meant for
+// testing and benchmarking - not safe to call while kernels are running.
+func SetSIMDLevel(level int) int {
+	prev := simdLevel
+	if level > simdSupported {
+		level = simdSupported
+	}
+	if level < SIMD_GENERIC {
+		level = SIMD_GENERIC
+	}
+	simdLevel = level
+	return prev
+}
+
+func init() {
+	simdSupported = detectSIMD()
+	simdLevel = simdSupported
+	if level, ok := simdNames[strings.ToLower(os.Getenv(SIMD_ENV))]; ok {
+		SetSIMDLevel(level)
+	}
+}
@@ -0,0 +1,31 @@
+//go:build !purego
+
+package vec32
+
+// see cpu_amd64.s
+func cpuid(eaxArg, ecxArg uint32) (eax, ebx, ecx, edx uint32)
+func xgetbv() (eax, edx uint32)
+
+// SSE2 is part of amd64, AVX2 needs FMA as well and the OS has to save
+// the YMM registers
+func detectSIMD() int {
+	maxLeaf, _, _, _ := cpuid(0, 0)
+	_, _, ecx1, _ := cpuid(1, 0)
+	const (
+		fma     = 1 << 12
+		osxsave = 1 << 27
+		avx     = 1 << 28
+	)
+	if maxLeaf < 7 || ecx1&(fma|osxsave|avx) != fma|osxsave|avx {
+		return SIMD_SSE
+	}
+	// XMM and YMM state enabled
+	if xcr0, _ := xgetbv(); xcr0&6 != 6 {
+		return SIMD_SSE
+	}
+	const avx2 = 1 << 5
+	if _, ebx7, _, _ := cpuid(7, 0); ebx7&avx2 == 0 {
+		return SIMD_SSE
+	}
+	return SIMD_AVX2
+}
@@ -0,0 +1,8 @@
+//go:build !amd64 || purego
+
+package vec32
+
+// the batch kernels have no assembly on other platforms
+func detectSIMD() int {
+	return SIMD_GENERIC
+}
@@ -0,0 +1,183 @@
+package vec32
+
+import (
+	"math/rand"
+	"testing"
+)
+
+var simdTestLevels = []struct {
+	name  string
+	level int
+}{
+	{"generic", SIMD_GENERIC},
+	{"sse", SIMD_SSE},
+	{"avx2", SIMD_AVX2},
+}
+
+func TestSetSIMDLevel(t *testing.T) {
+	prev := SetSIMDLevel(SIMD_AVX2 + 1)
+	defer SetSIMDLevel(prev)
+	if SIMDLevel() != simdSupported {
+		t.Errorf("SetSIMDLevel() above the supported level - expected %d got %d", simdSupported, SIMDLevel())
+	}
+	SetSIMDLevel(-1)
+	if SIMDLevel() != SIMD_GENERIC {
+		t.Errorf("SetSIMDLevel(-1) - expected %d got %d", SIMD_GENERIC, SIMDLevel())
+	}
+	if cur := SetSIMDLevel(SIMD_SSE); cur != SIMD_GENERIC {
+		t.Errorf("SetSIMDLevel() returned %d, expected %d", cur, SIMD_GENERIC)
+	}
+}
+
+func TestOrthoBox8(t *testing.T) {
+	rnd := rand.New(rand.NewSource(53))
+	var b OrthoBox8
+	boxes := make([]OrthoBox, 8)
+	for i := range boxes {
+		boxes[i] = randomOrthoBox(rnd)
+		b.Set(i, &boxes[i])
+	}
+	for i := range boxes {
+		bb := b.Box(i)
+		testBounds(t, "OrthoBox8.Box()", &boxes[i], &bb)
+	}
+}
+
+func TestSIMDKernels(t *testing.T) {
+	prev := SIMDLevel()
+	defer SetSIMDLevel(prev)
+	for _, l := range simdTestLevels {
+		if l.level > simdSupported {
+			t.Logf("skipping %s, not supported by the CPU", l.name)
+			continue
+		}
+		SetSIMDLevel(l.level)
+		rnd := rand.New(rand.NewSource(59))
+		for _, n := range []int{0, 1, 3, 4, 7, 8, 9, 15, 16, 17, 100} {
+			a := NewVec3SoAFromSlice(randomVec3s(rnd, n))
+			b := NewVec3SoAFromSlice(randomVec3s(rnd, n))
+			c := randomVec3(rnd)
+
+			expF, curF := make([]float32, n), make([]float32, n)
+			dotSoA3(a, &c, expF, 0, n)
+			DotSoA3(a, &c, curF)
+			testFloats(t, "DotSoA3() "+l.name, expF, curF)
+			lengthSoA3(a, expF, 0, n)
+			LengthSoA3(a, curF)
+			testFloats(t, "LengthSoA3() "+l.name, expF, curF)
+
+			exp, cur := NewVec3SoA(n), NewVec3SoA(n)
+			crossSoA3(a, b, exp, 0, n)
+			CrossSoA3(a, b, cur)
+			testVec3s(t, "CrossSoA3() "+l.name, exp.AppendTo(nil), cur.AppendTo(nil))
+
+			boxes := make([]OrthoBox, n)
+			for i := range boxes {
+				boxes[i] = randomOrthoBox(rnd)
+			}
+			expBB, curBB := ORTHO_EMPTY, ORTHO_EMPTY
+			boundsBoxes(boxes, &expBB)
+			BoundsBoxes(boxes, &curBB)
+			testBounds(t, "BoundsBoxes() "+l.name, &expBB, &curBB)
+		}
+	}
+}
+
+func TestSIMDOrthoBoxIntersect8(t *testing.T) {
+	prev := SIMDLevel()
+	defer SetSIMDLevel(prev)
+	for _, l := range simdTestLevels {
+		if l.level > simdSupported {
+			continue
+		}
+		SetSIMDLevel(l.level)
+		rnd := rand.New(rand.NewSource(61))
+		for i := 0; i < 200; i++ {
+			var b OrthoBox8
+			for j := 0; j < 8; j++ {
+				bb := randomOrthoBox(rnd)
+				b.Set(j, &bb)
+			}
+			// origins on the slabs and axis parallel rays lead to NaN
+			p0 := randomVec3(rnd)
+			if rnd.Intn(4) == 0 {
+				p0.X = b.P0[0][rnd.Intn(8)]
+			}
+			r := Ray{P0: p0, N: randomVec3(rnd)}
+			var ri RayInv
+			r.Inverse(&ri)
+			var expMin, expMax, curMin, curMax [8]float32
+			orthoBoxIntersect8(&b, &ri.P0, &ri.Inv, &expMin, &expMax)
+			OrthoBoxIntersect8(&b, &ri.P0, &ri.Inv, &curMin, &curMax)
+			if expMin != curMin || expMax != curMax {
+				t.Errorf("OrthoBoxIntersect8() %s differs from Go for ray %s %s - expected %v, %v got %v, %v",
+					l.name, r.P0.String(), r.N.String(), expMin, expMax, curMin, curMax)
+			}
+		}
+	}
+}
+
+func benchSIMD(b *testing.B, bytes int64, fn func()) {
+	prev := SIMDLevel()
+	defer SetSIMDLevel(prev)
+	for _, l := range simdTestLevels {
+		if l.level > simdSupported {
+			continue
+		}
+		b.Run(l.name, func(b *testing.B) {
+			SetSIMDLevel(l.level)
+			b.SetBytes(bytes)
+			for i := 0; i < b.N; i++ {
+				fn()
+			}
+		})
+	}
+}
+
+func BenchmarkSIMDDotSoA3(b *testing.B) {
+	v, _ := benchVec3s()
+	s := NewVec3SoAFromSlice(v)
+	out := make([]float32, benchBatchSize)
+	c := NewVec3(1, 2, 3)
+	benchSIMD(b, benchBatchSize*12, func() { DotSoA3(s, &c, out) })
+}
+
+func BenchmarkSIMDCrossSoA3(b *testing.B) {
+	v, _ := benchVec3s()
+	s := NewVec3SoAFromSlice(v)
+	out := NewVec3SoA(benchBatchSize)
+	benchSIMD(b, benchBatchSize*24, func() { CrossSoA3(s, s, out) })
+}
+
+func BenchmarkSIMDLengthSoA3(b *testing.B) {
+	v, _ := benchVec3s()
+	s := NewVec3SoAFromSlice(v)
+	out := make([]float32, benchBatchSize)
+	benchSIMD(b, benchBatchSize*12, func() { LengthSoA3(s, out) })
+}
+
+func BenchmarkSIMDBoundsBoxes(b *testing.B) {
+	rnd := rand.New(rand.NewSource(37))
+	boxes := make([]OrthoBox, benchBatchSize)
+	for i := range boxes {
+		boxes[i] = randomOrthoBox(rnd)
+	}
+	benchSIMD(b, benchBatchSize*32, func() {
+		bb := ORTHO_EMPTY
+		BoundsBoxes(boxes, &bb)
+	})
+}
+
+func BenchmarkSIMDOrthoBoxIntersect8(b *testing.B) {
+	rnd := rand.New(rand.NewSource(37))
+	var boxes OrthoBox8
+	for i := 0; i < 8; i++ {
+		bb := randomOrthoBox(rnd)
+		boxes.Set(i, &bb)
+	}
+	r := Ray{P0: NewVec3(-20, -15, -10), N: NewVec3(1, 0.8, 0.6)}
+	var ri RayInv
+	r.Inverse(&ri)
+	var tmin, tmax [8]float32
+	benchSIMD(b, 8*24, func() { OrthoBoxIntersect8(&boxes, &ri.P0, &ri.Inv, &tmin, &tmax) })
+}
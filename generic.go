@@ -36,11 +36,22 @@ func AlmostEqual(a, b float32) bool {
 	return 2*diff/(a+b) < 10*EPS
 }
 
+// AlmostEqual() in R2
+func AlmostEqual2(a, b *Vec2) bool {
+	return AlmostEqual(a.X, b.X) && AlmostEqual(a.Y, b.Y)
+}
+
 // AlmostEqual() in R3
 func AlmostEqual3(a, b *Vec3) bool {
 	return AlmostEqual(a.X, b.X) && AlmostEqual(a.Y, b.Y) && AlmostEqual(a.Z, b.Z)
 }
 
+// AlmostEqual() in R4
+func AlmostEqual4(a, b *Vec4) bool {
+	return AlmostEqual(a.X, b.X) && AlmostEqual(a.Y, b.Y) &&
+		AlmostEqual(a.Z, b.Z) && AlmostEqual(a.W, b.W)
+}
+
 // f32-fabs
 func Abs(v float32) float32 {
 	if v >= 0 {
@@ -89,3 +100,28 @@ func Min(a, b float32) float32 {
 	}
 	return b
 }
+
+// Return Sin (see math.Sin())
+func Sin(v float32) float32 {
+	return float32(math.Sin(float64(v)))
+}
+
+// Return Cos (see math.Cos())
+func Cos(v float32) float32 {
+	return float32(math.Cos(float64(v)))
+}
+
+// Return Tan (see math.Tan())
+func Tan(v float32) float32 {
+	return float32(math.Tan(float64(v)))
+}
+
+// Return Acos (see math.Acos())
+func Acos(v float32) float32 {
+	return float32(math.Acos(float64(v)))
+}
+
+// Return Atan2 (see math.Atan2())
+func Atan2(y, x float32) float32 {
+	return float32(math.Atan2(float64(y), float64(x)))
+}
@@ -1,6 +1,7 @@
 package vec32
 
 import (
+	"math/rand"
 	"testing"
 )
 
@@ -48,6 +49,103 @@ func TestIntersect(t *testing.T) {
 	}
 }
 
+func TestIntersectOrthoBox(t *testing.T) {
+	bb := OrthoBox{NewVec3(0, 0, 0), NewVec3(1, 1, 1)}
+	var cases = []struct {
+		ray        Ray
+		tmin, tmax float32
+		hit        bool
+	}{
+		{Ray{NewVec3(0.5, 0.5, -2), NewVec3(0, 0, 1)}, 2, 3, true},
+		{Ray{NewVec3(0.5, 0.5, 3), NewVec3(0, 0, -1)}, 2, 3, true},
+		{Ray{NewVec3(0.5, 0.5, 0.5), NewVec3(1, 0, 0)}, -0.5, 0.5, true},
+		{Ray{NewVec3(2, 0.5, 0.5), NewVec3(1, 0, 0)}, -2, -1, true},
+		{Ray{NewVec3(1.5, 0.5, -2), NewVec3(0, 0, 1)}, 0, 0, false},
+		{Ray{NewVec3(0.5, -0.5, -2), NewVec3(0, 0, 1)}, 0, 0, false},
+		// origin on the slab of a parallel axis
+		{Ray{NewVec3(0, 0.5, -2), NewVec3(0, 0, 1)}, 2, 3, true},
+		{Ray{NewVec3(1, 1, -2), NewVec3(0, 0, 1)}, 2, 3, true},
+		{Ray{NewVec3(0.5, 1, -2), NewVec3(-0, -0, 1)}, 2, 3, true},
+		{Ray{NewVec3(-1, -1, -1), unitVec3(1, 1, 1)}, Sqrt(3), 2 * Sqrt(3), true},
+	}
+	for i, tc := range cases {
+		var ri RayInv
+		tc.ray.Inverse(&ri)
+		results := [][2]float32{}
+		tmin, tmax := tc.ray.IntersectOrthoBox(&bb)
+		results = append(results, [2]float32{tmin, tmax})
+		tmin, tmax = ri.IntersectOrthoBox(&bb)
+		results = append(results, [2]float32{tmin, tmax})
+		tmin, tmax = OrthoBoxIntersect(&bb, &ri.P0, &ri.Inv)
+		results = append(results, [2]float32{tmin, tmax})
+		tmin, tmax = orthoBoxIntersect(&bb, &ri.P0, &ri.Inv)
+		results = append(results, [2]float32{tmin, tmax})
+		for j, res := range results {
+			if hit := res[0] <= res[1]; hit != tc.hit {
+				t.Errorf("tc %d/%d: expected hit == %t, got [%f, %f]", i, j, tc.hit, res[0], res[1])
+			} else if hit && (!AlmostEqual(res[0], tc.tmin) || !AlmostEqual(res[1], tc.tmax)) {
+				t.Errorf("tc %d/%d: expected [%f, %f], got [%f, %f]",
+					i, j, tc.tmin, tc.tmax, res[0], res[1])
+			}
+		}
+	}
+}
+
+func TestIntersectOrthoBoxRandom(t *testing.T) {
+	rnd := rand.New(rand.NewSource(3))
+	coord := func() float32 {
+		// small integers, so the origin lies on a slab every now and then
+		return float32(rnd.Intn(7) - 3)
+	}
+	for i := 0; i < 10000; i++ {
+		bb := ORTHO_EMPTY
+		p := NewVec3(coord(), coord(), coord())
+		bb.Add(&OrthoBox{p, p})
+		p = NewVec3(coord(), coord(), coord())
+		bb.Add(&OrthoBox{p, p})
+		r := Ray{NewVec3(coord(), coord(), coord()), NewVec3(coord(), coord(), coord())}
+		var ri RayInv
+		r.Inverse(&ri)
+		tmin, tmax := ri.IntersectOrthoBox(&bb)
+		tminAsm, tmaxAsm := OrthoBoxIntersect(&bb, &ri.P0, &ri.Inv)
+		tminGo, tmaxGo := orthoBoxIntersect(&bb, &ri.P0, &ri.Inv)
+		if tmin != tminAsm || tmax != tmaxAsm || tmin != tminGo || tmax != tmaxGo {
+			t.Errorf("tc %d: %s %s: results differ: [%f, %f] [%f, %f] [%f, %f]",
+				i, bb.String(), r.N.String(), tmin, tmax, tminAsm, tmaxAsm, tminGo, tmaxGo)
+		}
+	}
+}
+
+func BenchmarkIntersectOrthoBox(b *testing.B) {
+	bb := OrthoBox{NewVec3(0, 0, 0), NewVec3(1, 1, 1)}
+	r := Ray{NewVec3(0.3, 0.4, -2), unitVec3(0.1, 0.1, 1)}
+	var ri RayInv
+	r.Inverse(&ri)
+	for i := 0; i < b.N; i++ {
+		ri.IntersectOrthoBox(&bb)
+	}
+}
+
+func BenchmarkIntersectOrthoBoxAsm(b *testing.B) {
+	bb := OrthoBox{NewVec3(0, 0, 0), NewVec3(1, 1, 1)}
+	r := Ray{NewVec3(0.3, 0.4, -2), unitVec3(0.1, 0.1, 1)}
+	var ri RayInv
+	r.Inverse(&ri)
+	for i := 0; i < b.N; i++ {
+		OrthoBoxIntersect(&bb, &ri.P0, &ri.Inv)
+	}
+}
+
+func BenchmarkIntersectOrthoBoxGeneric(b *testing.B) {
+	bb := OrthoBox{NewVec3(0, 0, 0), NewVec3(1, 1, 1)}
+	r := Ray{NewVec3(0.3, 0.4, -2), unitVec3(0.1, 0.1, 1)}
+	var ri RayInv
+	r.Inverse(&ri)
+	for i := 0; i < b.N; i++ {
+		orthoBoxIntersect(&bb, &ri.P0, &ri.Inv)
+	}
+}
+
 func testOrthoBox(t *testing.T, i int, obj *Triangle, p0, p1 Vec3) {
 	var box OrthoBox
 	obj.OrthoBox(&box)
@@ -60,3 +158,8 @@ func testOrthoBox(t *testing.T, i int, obj *Triangle, p0, p1 Vec3) {
 			p1.String(), box.P1.String())
 	}
 }
+
+func unitVec3(x, y, z float32) Vec3 {
+	v := NewVec3(x, y, z)
+	return *v.Normalize()
+}
@@ -0,0 +1,144 @@
+package vec32
+
+import (
+	"fmt"
+)
+
+// Create the identity matrix
+func NewMat3Identity() Mat3 {
+	return Mat3{
+		{1, 0, 0},
+		{0, 1, 0},
+		{0, 0, 1},
+	}
+}
+
+// Create a scaling by the components of v
+func NewMat3Scale(v *Vec3) Mat3 {
+	return Mat3{
+		{v.X, 0, 0},
+		{0, v.Y, 0},
+		{0, 0, v.Z},
+	}
+}
+
+// Create a rotation about axis by angle (radians, counter clockwise)
+func NewMat3Rotate(axis *Vec3, angle float32) Mat3 {
+	a := axis.Normalize()
+	c := Cos(angle)
+	s := Sin(angle)
+	t := 1 - c
+	return Mat3{
+		{t*a.X*a.X + c, t*a.X*a.Y + s*a.Z, t*a.X*a.Z - s*a.Y},
+		{t*a.X*a.Y - s*a.Z, t*a.Y*a.Y + c, t*a.Y*a.Z + s*a.X},
+		{t*a.X*a.Z + s*a.Y, t*a.Y*a.Z - s*a.X, t*a.Z*a.Z + c},
+	}
+}
+
+// string representation (octave style)
+func (m *Mat3) String() string {
+	return fmt.Sprintf("[%g %g %g; %g %g %g; %g %g %g]",
+		m[0][0], m[1][0], m[2][0],
+		m[0][1], m[1][1], m[2][1],
+		m[0][2], m[1][2], m[2][2])
+}
+
+// Multiply two matrices
+func (m *Mat3) Mul(m2 *Mat3) *Mat3 {
+	r := new(Mat3)
+	MulMat3(m, m2, r)
+	return r
+}
+
+// Multiply two matrices (explicit)
+//
+// c may be the same as a or b
+func MulMat3(a, b, c *Mat3) {
+	var r Mat3
+	for col := 0; col < 3; col++ {
+		for row := 0; row < 3; row++ {
+			r[col][row] = a[0][row]*b[col][0] + a[1][row]*b[col][1] + a[2][row]*b[col][2]
+		}
+	}
+	*c = r
+}
+
+// Multiply the matrix with a vector
+func (m *Mat3) MulVec3(v *Vec3) *Vec3 {
+	r := new(Vec3)
+	MulMat3Vec3(m, v, r)
+	return r
+}
+
+// Multiply the matrix with a vector (explicit)
+func MulMat3Vec3(m *Mat3, v, out *Vec3) {
+	x, y, z := v.X, v.Y, v.Z
+	out.X = m[0][0]*x + m[1][0]*y + m[2][0]*z
+	out.Y = m[0][1]*x + m[1][1]*y + m[2][1]*z
+	out.Z = m[0][2]*x + m[1][2]*y + m[2][2]*z
+}
+
+// Get the transposed matrix
+func (m *Mat3) Transpose() *Mat3 {
+	return &Mat3{
+		{m[0][0], m[1][0], m[2][0]},
+		{m[0][1], m[1][1], m[2][1]},
+		{m[0][2], m[1][2], m[2][2]},
+	}
+}
+
+// Determinant
+func (m *Mat3) Determinant() float32 {
+	return m[0][0]*(m[1][1]*m[2][2]-m[2][1]*m[1][2]) -
+		m[1][0]*(m[0][1]*m[2][2]-m[2][1]*m[0][2]) +
+		m[2][0]*(m[0][1]*m[1][2]-m[1][1]*m[0][2])
+}
+
+// Get the inverse matrix
+//
+// returns false, if the matrix is singular
+func (m *Mat3) Inverse() (*Mat3, bool) {
+	r := new(Mat3)
+	ok := InverseMat3(m, r)
+	return r, ok
+}
+
+// Get the inverse matrix (explicit)
+//
+// returns false and leaves inv untouched, if the matrix is singular
+func InverseMat3(m, inv *Mat3) bool {
+	det := m.Determinant()
+	if det == 0 || IsNaN(det) {
+		return false
+	}
+	s := 1 / det
+	*inv = Mat3{
+		{(m[1][1]*m[2][2] - m[2][1]*m[1][2]) * s,
+			(m[2][1]*m[0][2] - m[0][1]*m[2][2]) * s,
+			(m[0][1]*m[1][2] - m[1][1]*m[0][2]) * s},
+		{(m[2][0]*m[1][2] - m[1][0]*m[2][2]) * s,
+			(m[0][0]*m[2][2] - m[2][0]*m[0][2]) * s,
+			(m[1][0]*m[0][2] - m[0][0]*m[1][2]) * s},
+		{(m[1][0]*m[2][1] - m[2][0]*m[1][1]) * s,
+			(m[2][0]*m[0][1] - m[0][0]*m[2][1]) * s,
+			(m[0][0]*m[1][1] - m[1][0]*m[0][1]) * s},
+	}
+	return true
+}
+
+// Equal
+func (m *Mat3) IsEqual(m2 *Mat3) bool {
+	return *m == *m2
+}
+
+// AlmostEqual() for all elements
+func AlmostEqualMat3(a, b *Mat3) bool {
+	for col := 0; col < 3; col++ {
+		for row := 0; row < 3; row++ {
+			if !AlmostEqual(a[col][row], b[col][row]) {
+				return false
+			}
+		}
+	}
+	return true
+}
@@ -0,0 +1,89 @@
+package vec32
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func testMat3(t *testing.T, name string, exp, cur *Mat3) {
+	for col := 0; col < 3; col++ {
+		for row := 0; row < 3; row++ {
+			if Abs(exp[col][row]-cur[col][row]) > 1e-4 {
+				t.Errorf("%s is wrong - expected %s got %s", name, exp.String(), cur.String())
+				return
+			}
+		}
+	}
+}
+
+// like testVec3(), but for results with rounding errors of trigonometric functions
+func testVec3Near(t *testing.T, name string, exp, cur Vec3) {
+	v := exp.Sub(&cur)
+	if v.Length() > 1e-5 {
+		t.Errorf("%s is wrong - expected %s got %s", name, exp.String(), cur.String())
+	}
+}
+
+func randomMat3(rnd *rand.Rand) Mat3 {
+	var m Mat3
+	for col := 0; col < 3; col++ {
+		for row := 0; row < 3; row++ {
+			m[col][row] = rnd.Float32()*2 - 1
+		}
+	}
+	return m
+}
+
+func TestMat3(t *testing.T) {
+	m := Mat3{{1, 4, 7}, {2, 5, 8}, {3, 6, 10}}
+	id := NewMat3Identity()
+	testString(t, "String()", "[1 2 3; 4 5 6; 7 8 10]", m.String())
+	testMat3(t, "Mul(identity)", &m, m.Mul(&id))
+	testMat3(t, "identity.Mul()", &m, id.Mul(&m))
+	testMat3(t, "Transpose()", &Mat3{{1, 2, 3}, {4, 5, 6}, {7, 8, 10}}, m.Transpose())
+	testFloat(t, "Determinant()", -3, m.Determinant())
+	v := NewVec3(1, 1, 1)
+	testVec3(t, "MulVec3()", NewVec3(6, 15, 25), *m.MulVec3(&v))
+	s := NewVec3(2, 3, 4)
+	sm := NewMat3Scale(&s)
+	testVec3(t, "scale", NewVec3(2, 3, 4), *sm.MulVec3(&v))
+	if !m.IsEqual(&m) || m.IsEqual(&id) {
+		t.Errorf("IsEqual() is wrong")
+	}
+}
+
+func TestMat3Inverse(t *testing.T) {
+	rnd := rand.New(rand.NewSource(11))
+	id := NewMat3Identity()
+	for i := 0; i < 100; i++ {
+		m := randomMat3(rnd)
+		if Abs(m.Determinant()) < 0.1 {
+			continue
+		}
+		inv, ok := m.Inverse()
+		if !ok {
+			t.Fatalf("%s should be invertible", m.String())
+		}
+		testMat3(t, "m * inv", &id, m.Mul(inv))
+		testMat3(t, "inv * m", &id, inv.Mul(&m))
+	}
+	singular := Mat3{{1, 2, 3}, {2, 4, 6}, {0, 1, 0}}
+	inv := id
+	if InverseMat3(&singular, &inv) || !inv.IsEqual(&id) {
+		t.Errorf("singular matrix should not be inverted")
+	}
+}
+
+func TestMat3Rotate(t *testing.T) {
+	axis := NewVec3(0, 0, 2)
+	r := NewMat3Rotate(&axis, math.Pi/2)
+	x := NewVec3(1, 0, 0)
+	testVec3Near(t, "rotate x about z", NewVec3(0, 1, 0), *r.MulVec3(&x))
+	axis = NewVec3(1, 1, 1)
+	r = NewMat3Rotate(&axis, 2*math.Pi/3)
+	testVec3Near(t, "rotate x about diagonal", NewVec3(0, 1, 0), *r.MulVec3(&x))
+	// rotations are orthonormal
+	testMat3(t, "transpose is inverse", r.Transpose(), func() *Mat3 { inv, _ := r.Inverse(); return inv }())
+	testFloat(t, "Determinant()", 1, r.Determinant())
+}
@@ -0,0 +1,257 @@
+package vec32
+
+import (
+	"fmt"
+	"unsafe"
+)
+
+// Create the identity matrix
+func NewMat4Identity() Mat4 {
+	return Mat4{
+		{1, 0, 0, 0},
+		{0, 1, 0, 0},
+		{0, 0, 1, 0},
+		{0, 0, 0, 1},
+	}
+}
+
+// Create a translation by v
+func NewMat4Translate(v *Vec3) Mat4 {
+	m := NewMat4Identity()
+	m[3][0] = v.X
+	m[3][1] = v.Y
+	m[3][2] = v.Z
+	return m
+}
+
+// Create a scaling by the components of v
+func NewMat4Scale(v *Vec3) Mat4 {
+	m := NewMat4Identity()
+	m[0][0] = v.X
+	m[1][1] = v.Y
+	m[2][2] = v.Z
+	return m
+}
+
+// Create a rotation about axis by angle (radians, counter clockwise)
+func NewMat4Rotate(axis *Vec3, angle float32) Mat4 {
+	r := NewMat3Rotate(axis, angle)
+	return NewMat4FromMat3(&r)
+}
+
+// Create a view matrix looking from eye at center (see gluLookAt())
+func NewMat4LookAt(eye, center, up *Vec3) Mat4 {
+	f := center.Sub(eye).Normalize()
+	s := f.Cross(up).Normalize()
+	u := s.Cross(f)
+	return Mat4{
+		{s.X, u.X, -f.X, 0},
+		{s.Y, u.Y, -f.Y, 0},
+		{s.Z, u.Z, -f.Z, 0},
+		{-s.Dot(eye), -u.Dot(eye), f.Dot(eye), 1},
+	}
+}
+
+// Create a perspective projection (see gluPerspective())
+//
+// fovy is the vertical field of view in radians
+func NewMat4Perspective(fovy, aspect, near, far float32) Mat4 {
+	f := 1 / Tan(fovy/2)
+	return Mat4{
+		{f / aspect, 0, 0, 0},
+		{0, f, 0, 0},
+		{0, 0, (far + near) / (near - far), -1},
+		{0, 0, 2 * far * near / (near - far), 0},
+	}
+}
+
+// Create an orthographic projection (see glOrtho())
+func NewMat4Ortho(left, right, bottom, top, near, far float32) Mat4 {
+	return Mat4{
+		{2 / (right - left), 0, 0, 0},
+		{0, 2 / (top - bottom), 0, 0},
+		{0, 0, -2 / (far - near), 0},
+		{-(right + left) / (right - left), -(top + bottom) / (top - bottom), -(far + near) / (far - near), 1},
+	}
+}
+
+// Create a matrix with m as rotational part
+func NewMat4FromMat3(m *Mat3) Mat4 {
+	return Mat4{
+		{m[0][0], m[0][1], m[0][2], 0},
+		{m[1][0], m[1][1], m[1][2], 0},
+		{m[2][0], m[2][1], m[2][2], 0},
+		{0, 0, 0, 1},
+	}
+}
+
+// Get the upper left 3x3 matrix
+func (m *Mat4) Mat3() Mat3 {
+	return Mat3{
+		{m[0][0], m[0][1], m[0][2]},
+		{m[1][0], m[1][1], m[1][2]},
+		{m[2][0], m[2][1], m[2][2]},
+	}
+}
+
+// string representation (octave style)
+func (m *Mat4) String() string {
+	return fmt.Sprintf("[%g %g %g %g; %g %g %g %g; %g %g %g %g; %g %g %g %g]",
+		m[0][0], m[1][0], m[2][0], m[3][0],
+		m[0][1], m[1][1], m[2][1], m[3][1],
+		m[0][2], m[1][2], m[2][2], m[3][2],
+		m[0][3], m[1][3], m[2][3], m[3][3])
+}
+
+// Multiply two matrices
+func (m *Mat4) Mul(m2 *Mat4) *Mat4 {
+	r := new(Mat4)
+	MulMat4(m, m2, r)
+	return r
+}
+
+// Multiply two matrices (explicit)
+//
+// c may be the same as a or b
+func MulMat4(a, b, c *Mat4) {
+	var r Mat4
+	for col := 0; col < 4; col++ {
+		for row := 0; row < 4; row++ {
+			r[col][row] = a[0][row]*b[col][0] + a[1][row]*b[col][1] +
+				a[2][row]*b[col][2] + a[3][row]*b[col][3]
+		}
+	}
+	*c = r
+}
+
+// Get the transposed matrix
+func (m *Mat4) Transpose() *Mat4 {
+	r := new(Mat4)
+	for col := 0; col < 4; col++ {
+		for row := 0; row < 4; row++ {
+			r[col][row] = m[row][col]
+		}
+	}
+	return r
+}
+
+// Determinant
+func (m *Mat4) Determinant() float32 {
+	var inv Mat4
+	return m.cofactors(&inv)
+}
+
+// Get the inverse matrix
+//
+// returns false, if the matrix is singular
+func (m *Mat4) Inverse() (*Mat4, bool) {
+	r := new(Mat4)
+	ok := InverseMat4(m, r)
+	return r, ok
+}
+
+// Get the inverse matrix (explicit)
+//
+// returns false and leaves inv untouched, if the matrix is singular
+func InverseMat4(m, inv *Mat4) bool {
+	var adj Mat4
+	det := m.cofactors(&adj)
+	if det == 0 || IsNaN(det) {
+		return false
+	}
+	s := 1 / det
+	for col := 0; col < 4; col++ {
+		for row := 0; row < 4; row++ {
+			inv[col][row] = adj[col][row] * s
+		}
+	}
+	return true
+}
+
+// calculate the adjugate matrix and return the determinant
+func (m *Mat4) cofactors(adj *Mat4) float32 {
+	// both are column-major, like the formula expects
+	a := (*[16]float32)(unsafe.Pointer(m))
+	r := (*[16]float32)(unsafe.Pointer(adj))
+
+	r[0] = a[5]*a[10]*a[15] - a[5]*a[11]*a[14] - a[9]*a[6]*a[15] +
+		a[9]*a[7]*a[14] + a[13]*a[6]*a[11] - a[13]*a[7]*a[10]
+	r[4] = -a[4]*a[10]*a[15] + a[4]*a[11]*a[14] + a[8]*a[6]*a[15] -
+		a[8]*a[7]*a[14] - a[12]*a[6]*a[11] + a[12]*a[7]*a[10]
+	r[8] = a[4]*a[9]*a[15] - a[4]*a[11]*a[13] - a[8]*a[5]*a[15] +
+		a[8]*a[7]*a[13] + a[12]*a[5]*a[11] - a[12]*a[7]*a[9]
+	r[12] = -a[4]*a[9]*a[14] + a[4]*a[10]*a[13] + a[8]*a[5]*a[14] -
+		a[8]*a[6]*a[13] - a[12]*a[5]*a[10] + a[12]*a[6]*a[9]
+	r[1] = -a[1]*a[10]*a[15] + a[1]*a[11]*a[14] + a[9]*a[2]*a[15] -
+		a[9]*a[3]*a[14] - a[13]*a[2]*a[11] + a[13]*a[3]*a[10]
+	r[5] = a[0]*a[10]*a[15] - a[0]*a[11]*a[14] - a[8]*a[2]*a[15] +
+		a[8]*a[3]*a[14] + a[12]*a[2]*a[11] - a[12]*a[3]*a[10]
+	r[9] = -a[0]*a[9]*a[15] + a[0]*a[11]*a[13] + a[8]*a[1]*a[15] -
+		a[8]*a[3]*a[13] - a[12]*a[1]*a[11] + a[12]*a[3]*a[9]
+	r[13] = a[0]*a[9]*a[14] - a[0]*a[10]*a[13] - a[8]*a[1]*a[14] +
+		a[8]*a[2]*a[13] + a[12]*a[1]*a[10] - a[12]*a[2]*a[9]
+	r[2] = a[1]*a[6]*a[15] - a[1]*a[7]*a[14] - a[5]*a[2]*a[15] +
+		a[5]*a[3]*a[14] + a[13]*a[2]*a[7] - a[13]*a[3]*a[6]
+	r[6] = -a[0]*a[6]*a[15] + a[0]*a[7]*a[14] + a[4]*a[2]*a[15] -
+		a[4]*a[3]*a[14] - a[12]*a[2]*a[7] + a[12]*a[3]*a[6]
+	r[10] = a[0]*a[5]*a[15] - a[0]*a[7]*a[13] - a[4]*a[1]*a[15] +
+		a[4]*a[3]*a[13] + a[12]*a[1]*a[7] - a[12]*a[3]*a[5]
+	r[14] = -a[0]*a[5]*a[14] + a[0]*a[6]*a[13] + a[4]*a[1]*a[14] -
+		a[4]*a[2]*a[13] - a[12]*a[1]*a[6] + a[12]*a[2]*a[5]
+	r[3] = -a[1]*a[6]*a[11] + a[1]*a[7]*a[10] + a[5]*a[2]*a[11] -
+		a[5]*a[3]*a[10] - a[9]*a[2]*a[7] + a[9]*a[3]*a[6]
+	r[7] = a[0]*a[6]*a[11] - a[0]*a[7]*a[10] - a[4]*a[2]*a[11] +
+		a[4]*a[3]*a[10] + a[8]*a[2]*a[7] - a[8]*a[3]*a[6]
+	r[11] = -a[0]*a[5]*a[11] + a[0]*a[7]*a[9] + a[4]*a[1]*a[11] -
+		a[4]*a[3]*a[9] - a[8]*a[1]*a[7] + a[8]*a[3]*a[5]
+	r[15] = a[0]*a[5]*a[10] - a[0]*a[6]*a[9] - a[4]*a[1]*a[10] +
+		a[4]*a[2]*a[9] + a[8]*a[1]*a[6] - a[8]*a[2]*a[5]
+
+	return a[0]*r[0] + a[1]*r[4] + a[2]*r[8] + a[3]*r[12]
+}
+
+// Equal
+func (m *Mat4) IsEqual(m2 *Mat4) bool {
+	return *m == *m2
+}
+
+// AlmostEqual() for all elements
+func AlmostEqualMat4(a, b *Mat4) bool {
+	for col := 0; col < 4; col++ {
+		for row := 0; row < 4; row++ {
+			if !AlmostEqual(a[col][row], b[col][row]) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// Transform a point (w = 1) by an affine matrix
+func (v *Vec3) TransformPoint(m *Mat4) *Vec3 {
+	r := new(Vec3)
+	TransformPoint3(m, v, r)
+	return r
+}
+
+// Transform a direction (w = 0) by an affine matrix
+func (v *Vec3) TransformDirection(m *Mat4) *Vec3 {
+	r := new(Vec3)
+	TransformDirection3(m, v, r)
+	return r
+}
+
+func transformPoint3(m *Mat4, v, out *Vec3) {
+	x, y, z := v.X, v.Y, v.Z
+	out.X = m[0][0]*x + m[1][0]*y + m[2][0]*z + m[3][0]
+	out.Y = m[0][1]*x + m[1][1]*y + m[2][1]*z + m[3][1]
+	out.Z = m[0][2]*x + m[1][2]*y + m[2][2]*z + m[3][2]
+}
+
+// Transform a direction (explicit)
+func TransformDirection3(m *Mat4, v, out *Vec3) {
+	x, y, z := v.X, v.Y, v.Z
+	out.X = m[0][0]*x + m[1][0]*y + m[2][0]*z
+	out.Y = m[0][1]*x + m[1][1]*y + m[2][1]*z
+	out.Z = m[0][2]*x + m[1][2]*y + m[2][2]*z
+}
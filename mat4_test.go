@@ -0,0 +1,132 @@
+package vec32
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func testMat4(t *testing.T, name string, exp, cur *Mat4) {
+	for col := 0; col < 4; col++ {
+		for row := 0; row < 4; row++ {
+			if Abs(exp[col][row]-cur[col][row]) > 1e-4 {
+				t.Errorf("%s is wrong - expected %s got %s", name, exp.String(), cur.String())
+				return
+			}
+		}
+	}
+}
+
+func randomMat4(rnd *rand.Rand) Mat4 {
+	var m Mat4
+	for col := 0; col < 4; col++ {
+		for row := 0; row < 4; row++ {
+			m[col][row] = rnd.Float32()*2 - 1
+		}
+	}
+	return m
+}
+
+func TestMat4(t *testing.T) {
+	m := Mat4{{1, 5, 9, 13}, {2, 6, 10, 14}, {3, 7, 11, 15}, {4, 8, 12, 16}}
+	id := NewMat4Identity()
+	testString(t, "String()", "[1 2 3 4; 5 6 7 8; 9 10 11 12; 13 14 15 16]", m.String())
+	testMat4(t, "Mul(identity)", &m, m.Mul(&id))
+	testMat4(t, "identity.Mul()", &m, id.Mul(&m))
+	testMat4(t, "Transpose()", &Mat4{{1, 2, 3, 4}, {5, 6, 7, 8}, {9, 10, 11, 12}, {13, 14, 15, 16}}, m.Transpose())
+	testFloat(t, "Determinant()", 0, m.Determinant())
+	d := Mat4{{2, 0, 0, 0}, {0, 3, 0, 0}, {0, 0, 4, 0}, {1, 2, 3, 1}}
+	testFloat(t, "Determinant()", 24, d.Determinant())
+
+	r := NewMat3Rotate(&Vec3{X: 1, Y: 2, Z: 3}, 0.5)
+	m4 := NewMat4FromMat3(&r)
+	m3 := m4.Mat3()
+	testMat3(t, "Mat3()", &r, &m3)
+}
+
+func TestMat4Inverse(t *testing.T) {
+	rnd := rand.New(rand.NewSource(13))
+	id := NewMat4Identity()
+	for i := 0; i < 100; i++ {
+		m := randomMat4(rnd)
+		if Abs(m.Determinant()) < 0.1 {
+			continue
+		}
+		inv, ok := m.Inverse()
+		if !ok {
+			t.Fatalf("%s should be invertible", m.String())
+		}
+		testMat4(t, "m * inv", &id, m.Mul(inv))
+		testMat4(t, "inv * m", &id, inv.Mul(&m))
+	}
+	singular := Mat4{{1, 2, 3, 4}, {2, 4, 6, 8}, {0, 1, 0, 0}, {0, 0, 0, 1}}
+	inv := id
+	if InverseMat4(&singular, &inv) || !inv.IsEqual(&id) {
+		t.Errorf("singular matrix should not be inverted")
+	}
+}
+
+func TestMat4Transform(t *testing.T) {
+	tr := NewMat4Translate(&Vec3{X: 1, Y: 2, Z: 3})
+	s := NewMat4Scale(&Vec3{X: 2, Y: 2, Z: 2})
+	rot := NewMat4Rotate(&Vec3{Z: 1}, math.Pi/2)
+	// scale first, then rotate, then translate
+	m := tr.Mul(rot.Mul(&s))
+	p := NewVec3(1, 0, 0)
+	testVec3Near(t, "TransformPoint()", NewVec3(1, 4, 3), *p.TransformPoint(m))
+	testVec3Near(t, "TransformDirection()", NewVec3(0, 2, 0), *p.TransformDirection(m))
+	var out Vec3
+	TransformPoint3(&tr, &p, &out)
+	testVec3(t, "TransformPoint3()", NewVec3(2, 2, 3), out)
+	testFloat(t, "pad", 0, out.pad)
+
+	rnd := rand.New(rand.NewSource(17))
+	for i := 0; i < 100; i++ {
+		m := randomMat4(rnd)
+		v := NewVec3(rnd.Float32()*10-5, rnd.Float32()*10-5, rnd.Float32()*10-5)
+		var exp, cur Vec3
+		transformPoint3(&m, &v, &exp)
+		TransformPoint3(&m, &v, &cur)
+		testVec3(t, "TransformPoint3() vs Go", exp, cur)
+	}
+}
+
+func TestMat4View(t *testing.T) {
+	eye := NewVec3(0, 0, 5)
+	center := NewVec3(0, 0, 0)
+	up := NewVec3(0, 1, 0)
+	view := NewMat4LookAt(&eye, &center, &up)
+	testVec3Near(t, "LookAt(eye)", NewVec3(0, 0, 0), *eye.TransformPoint(&view))
+	testVec3Near(t, "LookAt(center)", NewVec3(0, 0, -5), *center.TransformPoint(&view))
+
+	proj := NewMat4Perspective(math.Pi/2, 1, 1, 10)
+	// the near and far plane are mapped to -1 and 1 after the perspective divide
+	for _, tc := range []struct{ z, ndc float32 }{{-1, -1}, {-10, 1}} {
+		x, y, z, w := proj[2][0]*tc.z, proj[2][1]*tc.z, proj[2][2]*tc.z+proj[3][2], proj[2][3]*tc.z
+		testFloat(t, "Perspective() x", 0, x)
+		testFloat(t, "Perspective() y", 0, y)
+		testFloat(t, "Perspective() z", tc.ndc, z/w)
+	}
+
+	ortho := NewMat4Ortho(-2, 2, -1, 1, 1, 10)
+	p := NewVec3(2, -1, -10)
+	testVec3Near(t, "Ortho()", NewVec3(1, -1, 1), *p.TransformPoint(&ortho))
+}
+
+func BenchmarkTransformPoint3(b *testing.B) {
+	m := NewMat4Rotate(&Vec3{X: 1, Y: 2, Z: 3}, 0.5)
+	v := NewVec3(1, 2, 3)
+	var out Vec3
+	for i := 0; i < b.N; i++ {
+		TransformPoint3(&m, &v, &out)
+	}
+}
+
+func BenchmarkTransformPoint3Go(b *testing.B) {
+	m := NewMat4Rotate(&Vec3{X: 1, Y: 2, Z: 3}, 0.5)
+	v := NewVec3(1, 2, 3)
+	var out Vec3
+	for i := 0; i < b.N; i++ {
+		transformPoint3(&m, &v, &out)
+	}
+}
@@ -0,0 +1,157 @@
+package vec32
+
+import (
+	"strconv"
+	"unsafe"
+)
+
+// Get the index of a vertex in Verts
+//
+// p has to point into Verts (like the points of a Triangle do), otherwise
+// -1 is returned.
+func (m *Mesh) VertIndex(p *Vec3) int {
+	if len(m.Verts) == 0 || p == nil {
+		return -1
+	}
+	offset := uintptr(unsafe.Pointer(p)) - uintptr(unsafe.Pointer(&m.Verts[0]))
+	size := unsafe.Sizeof(m.Verts[0])
+	if offset%size != 0 || offset/size >= uintptr(len(m.Verts)) {
+		return -1
+	}
+	return int(offset / size)
+}
+
+// Get the vertex indices of a triangle
+//
+// returns false if a point of the triangle doesn't belong to Verts
+func (m *Mesh) TriIndices(tri *Triangle) (i1, i2, i3 int, ok bool) {
+	i1 = m.VertIndex(tri.P1)
+	i2 = m.VertIndex(tri.P2)
+	i3 = m.VertIndex(tri.P3)
+	return i1, i2, i3, i1 >= 0 && i2 >= 0 && i3 >= 0
+}
+
+// Get the number of triangles
+func (m *Mesh) TriCount() int {
+	if len(m.Indices) > 0 {
+		return len(m.Indices) / 3
+	}
+	return len(m.Tris)
+}
+
+// Get triangle i, derived from Indices if the mesh has them
+func (m *Mesh) Tri(i int) Triangle {
+	if len(m.Indices) == 0 {
+		return m.Tris[i]
+	}
+	return Triangle{
+		&m.Verts[m.Indices[3*i]],
+		&m.Verts[m.Indices[3*i+1]],
+		&m.Verts[m.Indices[3*i+2]],
+	}
+}
+
+// Get the vertex indices of triangle i
+//
+// Taken from Indices if the mesh has them, otherwise recovered from Tris.
+// returns false if an index is out of range.
+func (m *Mesh) TriVerts(i int) (i1, i2, i3 int, ok bool) {
+	if len(m.Indices) == 0 {
+		return m.TriIndices(&m.Tris[i])
+	}
+	n := uint32(len(m.Verts))
+	v1, v2, v3 := m.Indices[3*i], m.Indices[3*i+1], m.Indices[3*i+2]
+	return int(v1), int(v2), int(v3), v1 < n && v2 < n && v3 < n
+}
+
+// Build Tris from Indices for code using the legacy triangles
+//
+// They point into Verts and have to be built again when Verts grows or
+// Indices change.
+func (m *Mesh) SyncTris() {
+	m.Tris = make([]Triangle, len(m.Indices)/3)
+	for i := range m.Tris {
+		m.Tris[i] = m.Tri(i)
+	}
+}
+
+// Convert the legacy Tris to Indices and drop them
+//
+// The points of the triangles have to belong to Verts.
+func (m *Mesh) SyncIndices() error {
+	idx := make([]uint32, 3*len(m.Tris))
+	for i := range m.Tris {
+		i1, i2, i3, ok := m.TriIndices(&m.Tris[i])
+		if !ok {
+			return newErrorMesh("triangle " + strconv.Itoa(i) + " does not point into the vertices")
+		}
+		idx[3*i], idx[3*i+1], idx[3*i+2] = uint32(i1), uint32(i2), uint32(i3)
+	}
+	m.Indices = idx
+	m.Tris = nil
+	return nil
+}
+
+// check that all triangles refer to vertices
+func (m *Mesh) checkTris() error {
+	for i, n := 0, m.TriCount(); i < n; i++ {
+		if _, _, _, ok := m.TriVerts(i); !ok {
+			return newErrorMesh("triangle " + strconv.Itoa(i) + " does not point into the vertices")
+		}
+	}
+	return nil
+}
+
+// Get the interpolated normal at a ray hit of triangle triIdx
+//
+// The result is normalized. Without vertex normals the face normal is
+// used, returns false if the mesh has neither.
+func (m *Mesh) InterpolateNormal(triIdx int, hit *Intersection, n *Vec3) bool {
+	i1, i2, i3, ok := m.TriVerts(triIdx)
+	if !ok || len(m.Normals) != len(m.Verts) {
+		if len(m.FaceNormals) != m.TriCount() {
+			return false
+		}
+		*n = m.FaceNormals[triIdx]
+		return true
+	}
+	w := 1 - hit.u - hit.v
+	n1, n2, n3 := &m.Normals[i1], &m.Normals[i2], &m.Normals[i3]
+	*n = NewVec3(w*n1.X+hit.u*n2.X+hit.v*n3.X,
+		w*n1.Y+hit.u*n2.Y+hit.v*n3.Y,
+		w*n1.Z+hit.u*n2.Z+hit.v*n3.Z)
+	*n = n.Normalized()
+	return true
+}
+
+// Get the interpolated texture coordinate at a ray hit of triangle triIdx
+//
+// Returns false if the mesh has no texture coordinates.
+func (m *Mesh) InterpolateUV(triIdx int, hit *Intersection, uv *Vec2) bool {
+	i1, i2, i3, ok := m.TriVerts(triIdx)
+	if !ok || len(m.UVs) != len(m.Verts) {
+		return false
+	}
+	w := 1 - hit.u - hit.v
+	uv1, uv2, uv3 := &m.UVs[i1], &m.UVs[i2], &m.UVs[i3]
+	uv.X = w*uv1.X + hit.u*uv2.X + hit.v*uv3.X
+	uv.Y = w*uv1.Y + hit.u*uv2.Y + hit.v*uv3.Y
+	return true
+}
+
+// Get the interpolated color at a ray hit of triangle triIdx
+//
+// Returns false if the mesh has no colors.
+func (m *Mesh) InterpolateColor(triIdx int, hit *Intersection, c *Color) bool {
+	i1, i2, i3, ok := m.TriVerts(triIdx)
+	if !ok || len(m.Colors) != len(m.Verts) {
+		return false
+	}
+	w := 1 - hit.u - hit.v
+	c1, c2, c3 := &m.Colors[i1], &m.Colors[i2], &m.Colors[i3]
+	c.R = w*c1.R + hit.u*c2.R + hit.v*c3.R
+	c.G = w*c1.G + hit.u*c2.G + hit.v*c3.G
+	c.B = w*c1.B + hit.u*c2.B + hit.v*c3.B
+	c.A = w*c1.A + hit.u*c2.A + hit.v*c3.A
+	return true
+}
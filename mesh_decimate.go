@@ -0,0 +1,476 @@
+package vec32
+
+import (
+	"container/heap"
+	"math"
+)
+
+// options for Mesh.Decimate()
+//
+// Edges are collapsed until at most TargetTris triangles are left or the
+// next collapse would cost more than MaxError. The cost is the squared
+// distance of the new vertex to the planes of the former faces around it,
+// averaged by their area, so it scales like a squared length.
+// KeepBoundary fixes the vertices on boundaries, otherwise they are only
+// held back by a penalty. With Attributes, normals, texture coordinates
+// and colors are interpolated at the new vertex positions.
+type DecimateOptions struct {
+	TargetTris   int
+	MaxError     float32
+	KeepBoundary bool
+	Attributes   bool
+}
+
+func NewDecimateDefaultOptions() *DecimateOptions {
+	return &DecimateOptions{
+		TargetTris:   0,
+		MaxError:     INF,
+		KeepBoundary: true,
+		Attributes:   true,
+	}
+}
+
+// weight of the planes holding back boundaries without KeepBoundary
+const DECIMATE_BOUNDARY_WEIGHT = 1000
+
+// symmetric 4x4 matrix of a quadric error, upper triangle row by row
+type quadric [10]float64
+
+// the quadric of the squared distance to the plane n*p + d = 0, scaled by w
+func planeQuadric(n *Vec3, d, w float64) quadric {
+	a, b, c := float64(n.X), float64(n.Y), float64(n.Z)
+	return quadric{
+		w * a * a, w * a * b, w * a * c, w * a * d,
+		w * b * b, w * b * c, w * b * d,
+		w * c * c, w * c * d,
+		w * d * d,
+	}
+}
+
+func (q *quadric) add(q2 *quadric) {
+	for i := range q {
+		q[i] += q2[i]
+	}
+}
+
+func (q *quadric) eval(p *Vec3) float64 {
+	x, y, z := float64(p.X), float64(p.Y), float64(p.Z)
+	return q[0]*x*x + 2*q[1]*x*y + 2*q[2]*x*z + 2*q[3]*x +
+		q[4]*y*y + 2*q[5]*y*z + 2*q[6]*y +
+		q[7]*z*z + 2*q[8]*z + q[9]
+}
+
+// the position with the minimal error, false if there is none
+func (q *quadric) optimum(p *Vec3) bool {
+	a := [3][3]float64{{q[0], q[1], q[2]}, {q[1], q[4], q[5]}, {q[2], q[5], q[7]}}
+	b := [3]float64{-q[3], -q[6], -q[8]}
+	det := a[0][0]*(a[1][1]*a[2][2]-a[1][2]*a[2][1]) -
+		a[0][1]*(a[1][0]*a[2][2]-a[1][2]*a[2][0]) +
+		a[0][2]*(a[1][0]*a[2][1]-a[1][1]*a[2][0])
+	scale := math.Abs(a[0][0]) + math.Abs(a[1][1]) + math.Abs(a[2][2])
+	if scale == 0 || math.Abs(det) < 1e-10*scale*scale*scale {
+		return false
+	}
+	// Cramer's rule
+	var x [3]float64
+	for i := range x {
+		m := a
+		for r := 0; r < 3; r++ {
+			m[r][i] = b[r]
+		}
+		x[i] = (m[0][0]*(m[1][1]*m[2][2]-m[1][2]*m[2][1]) -
+			m[0][1]*(m[1][0]*m[2][2]-m[1][2]*m[2][0]) +
+			m[0][2]*(m[1][0]*m[2][1]-m[1][1]*m[2][0])) / det
+	}
+	*p = NewVec3(float32(x[0]), float32(x[1]), float32(x[2]))
+	return true
+}
+
+// a possible collapse of the edge u-v into u at p
+type collapse struct {
+	cost           float64
+	u, v           int
+	stampU, stampV int
+	p              Vec3
+}
+
+type collapseHeap []collapse
+
+func (h collapseHeap) Len() int            { return len(h) }
+func (h collapseHeap) Less(i, j int) bool  { return h[i].cost < h[j].cost }
+func (h collapseHeap) Swap(i, j int)       { h[i], h[j] = h[j], h[i] }
+func (h *collapseHeap) Push(x interface{}) { *h = append(*h, x.(collapse)) }
+func (h *collapseHeap) Pop() interface{} {
+	old := *h
+	c := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return c
+}
+
+type decimator struct {
+	m         *Mesh
+	opt       *DecimateOptions
+	faces     [][3]int
+	faceAlive []bool
+	nFaces    int
+	vertFaces [][]int
+	quadrics  []quadric
+	// the summed area of the planes in each quadric
+	areas    []float64
+	boundary []bool
+	// changed on every collapse at a vertex, outdates its queued collapses
+	stamps []int
+	queue  collapseHeap
+	// scratch buffers
+	ringU, ringV []int
+}
+
+// Simplify the mesh by collapsing edges (Garland-Heckbert quadric errors)
+//
+// The triangles keep their order, FaceNormals and Groups are updated.
+// Degenerated triangles and unused vertices are removed. Seams of split
+// vertices are boundaries, so welding the mesh first gives better results.
+func (m *Mesh) Decimate(opt *DecimateOptions) error {
+	if opt == nil {
+		opt = NewDecimateDefaultOptions()
+	}
+	idx, err := m.triIndexList()
+	if err != nil {
+		return err
+	}
+	d := decimator{m: m, opt: opt}
+	d.init(idx)
+	for d.nFaces > opt.TargetTris && len(d.queue) > 0 {
+		c := heap.Pop(&d.queue).(collapse)
+		if c.stampU != d.stamps[c.u] || c.stampV != d.stamps[c.v] {
+			continue
+		}
+		if c.cost > float64(opt.MaxError) {
+			break
+		}
+		if !d.canCollapse(c.u, c.v, &c.p) {
+			continue
+		}
+		d.collapse(c.u, c.v, &c.p)
+	}
+	d.finish()
+	return nil
+}
+
+func (d *decimator) init(idx []int) {
+	nVerts := len(d.m.Verts)
+	d.faces = make([][3]int, len(idx)/3)
+	d.faceAlive = make([]bool, len(d.faces))
+	d.vertFaces = make([][]int, nVerts)
+	d.quadrics = make([]quadric, nVerts)
+	d.areas = make([]float64, nVerts)
+	d.boundary = make([]bool, nVerts)
+	d.stamps = make([]int, nVerts)
+	for f := range d.faces {
+		a, b, c := idx[3*f], idx[3*f+1], idx[3*f+2]
+		d.faces[f] = [3]int{a, b, c}
+		if a == b || b == c || a == c {
+			continue
+		}
+		d.faceAlive[f] = true
+		d.nFaces += 1
+		for _, v := range d.faces[f] {
+			d.vertFaces[v] = append(d.vertFaces[v], f)
+		}
+		n, dist, area := d.plane(f)
+		q := planeQuadric(&n, dist, area)
+		for _, v := range d.faces[f] {
+			d.quadrics[v].add(&q)
+			d.areas[v] += area
+		}
+	}
+	d.findBoundaries()
+	for f, face := range d.faces {
+		if !d.faceAlive[f] {
+			continue
+		}
+		for k := 0; k < 3; k++ {
+			u, v := face[k], face[(k+1)%3]
+			// each inner edge is seen twice
+			if u < v || d.isBoundaryEdge(u, v) {
+				d.push(u, v)
+			}
+		}
+	}
+}
+
+// the unit normal, distance to the origin and area of a face
+func (d *decimator) plane(f int) (n Vec3, dist, area float64) {
+	p := &d.m.Verts
+	face := d.faces[f]
+	c := (*p)[face[1]].Minus((*p)[face[0]]).Crossed((*p)[face[2]].Minus((*p)[face[0]]))
+	l := c.Length()
+	if l == 0 {
+		return n, 0, 0
+	}
+	n = c.Scaled(1 / l)
+	return n, -float64(n.Inner((*p)[face[0]])), float64(l) / 2
+}
+
+func (d *decimator) findBoundaries() {
+	for f, face := range d.faces {
+		if !d.faceAlive[f] {
+			continue
+		}
+		for k := 0; k < 3; k++ {
+			u, v := face[k], face[(k+1)%3]
+			if !d.isBoundaryEdge(u, v) {
+				continue
+			}
+			d.boundary[u], d.boundary[v] = true, true
+			if d.opt.KeepBoundary {
+				continue
+			}
+			// a plane through the edge, perpendicular to the face
+			n, _, _ := d.plane(f)
+			e := d.m.Verts[v].Minus(d.m.Verts[u])
+			bn := e.Crossed(n)
+			if l := bn.Length(); l > 0 {
+				bn = bn.Scaled(1 / l)
+				q := planeQuadric(&bn, -float64(bn.Inner(d.m.Verts[u])),
+					DECIMATE_BOUNDARY_WEIGHT*float64(e.LengthSq()))
+				d.quadrics[u].add(&q)
+				d.quadrics[v].add(&q)
+			}
+		}
+	}
+}
+
+// count the live faces using both vertices
+func (d *decimator) edgeFaces(u, v int) int {
+	n := 0
+	for _, f := range d.vertFaces[u] {
+		if d.faceAlive[f] && d.hasVert(f, v) {
+			n += 1
+		}
+	}
+	return n
+}
+
+func (d *decimator) isBoundaryEdge(u, v int) bool {
+	return d.edgeFaces(u, v) == 1
+}
+
+func (d *decimator) hasVert(f, v int) bool {
+	face := &d.faces[f]
+	return face[0] == v || face[1] == v || face[2] == v
+}
+
+// queue the collapse of the edge u-v, into the vertex it can move to
+func (d *decimator) push(u, v int) {
+	if d.opt.KeepBoundary && d.boundary[u] && d.boundary[v] {
+		return
+	}
+	if d.opt.KeepBoundary && d.boundary[v] {
+		u, v = v, u
+	}
+	q := d.quadrics[u]
+	q.add(&d.quadrics[v])
+	var p Vec3
+	switch {
+	case d.opt.KeepBoundary && d.boundary[u]:
+		p = d.m.Verts[u]
+	case q.optimum(&p):
+	default:
+		// the best of the end points and the middle
+		pu, pv := d.m.Verts[u], d.m.Verts[v]
+		p = pu
+		best := q.eval(&pu)
+		for _, c := range [2]Vec3{pv, pu.Plus(pv).Scaled(0.5)} {
+			if e := q.eval(&c); e < best {
+				p, best = c, e
+			}
+		}
+	}
+	cost := q.eval(&p)
+	if area := d.areas[u] + d.areas[v]; area > 0 {
+		cost /= area
+	}
+	if cost < 0 {
+		// rounding
+		cost = 0
+	}
+	heap.Push(&d.queue, collapse{cost, u, v, d.stamps[u], d.stamps[v], p})
+}
+
+// the vertices sharing an edge with v, appended to ring[:0]
+func (d *decimator) ring(v int, ring []int) []int {
+	ring = ring[:0]
+	for _, f := range d.vertFaces[v] {
+		if !d.faceAlive[f] {
+			continue
+		}
+		for _, w := range d.faces[f] {
+			if w != v {
+				ring = append(ring, w)
+			}
+		}
+	}
+	return sortUnique(ring)
+}
+
+// check the link condition and that no face flips over
+func (d *decimator) canCollapse(u, v int, p *Vec3) bool {
+	// the only common neighbors are the third vertices of the faces at the
+	// edge, otherwise the collapse pinches the surface
+	d.ringU = d.ring(u, d.ringU)
+	d.ringV = d.ring(v, d.ringV)
+	common := 0
+	for i, j := 0, 0; i < len(d.ringU) && j < len(d.ringV); {
+		switch {
+		case d.ringU[i] < d.ringV[j]:
+			i++
+		case d.ringU[i] > d.ringV[j]:
+			j++
+		default:
+			common += 1
+			i++
+			j++
+		}
+	}
+	nFaces := d.edgeFaces(u, v)
+	if common != nFaces {
+		return false
+	}
+	// a tetrahedron would become two faces back to back
+	if nFaces == 2 && len(d.ringU) == 3 && len(d.ringV) == 3 {
+		return false
+	}
+	// joining two boundaries by an inner edge pinches as well
+	if d.boundary[u] && d.boundary[v] && !d.isBoundaryEdge(u, v) {
+		return false
+	}
+	for _, w := range [2]int{u, v} {
+		for _, f := range d.vertFaces[w] {
+			if !d.faceAlive[f] || (d.hasVert(f, u) && d.hasVert(f, v)) {
+				continue
+			}
+			if d.flips(f, w, p) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// check if moving vertex w of face f to p flips or degenerates the face
+func (d *decimator) flips(f, w int, p *Vec3) bool {
+	var pts [3]Vec3
+	for k, v := range d.faces[f] {
+		pts[k] = d.m.Verts[v]
+	}
+	before := pts[1].Minus(pts[0]).Crossed(pts[2].Minus(pts[0]))
+	for k, v := range d.faces[f] {
+		if v == w {
+			pts[k] = *p
+		}
+	}
+	after := pts[1].Minus(pts[0]).Crossed(pts[2].Minus(pts[0]))
+	return before.Inner(after) <= 0
+}
+
+// collapse v into u, moving u to p
+func (d *decimator) collapse(u, v int, p *Vec3) {
+	d.interpolate(u, v, p)
+	d.m.Verts[u] = *p
+	d.quadrics[u].add(&d.quadrics[v])
+	d.areas[u] += d.areas[v]
+	d.boundary[u] = d.boundary[u] || d.boundary[v]
+	d.stamps[u] += 1
+	d.stamps[v] += 1
+	for _, f := range d.vertFaces[v] {
+		if !d.faceAlive[f] {
+			continue
+		}
+		if d.hasVert(f, u) {
+			d.faceAlive[f] = false
+			d.nFaces -= 1
+			continue
+		}
+		for k := range d.faces[f] {
+			if d.faces[f][k] == v {
+				d.faces[f][k] = u
+			}
+		}
+		d.vertFaces[u] = append(d.vertFaces[u], f)
+	}
+	d.vertFaces[v] = nil
+	// drop the dead faces and queue the new edges
+	faces := d.vertFaces[u][:0]
+	for _, f := range d.vertFaces[u] {
+		if d.faceAlive[f] {
+			faces = append(faces, f)
+		}
+	}
+	d.vertFaces[u] = faces
+	// the costs of the other edges don't depend on u
+	d.ringU = d.ring(u, d.ringU)
+	for _, w := range d.ringU {
+		d.push(u, w)
+	}
+}
+
+// set the attributes of u to the ones at p on the edge u-v
+func (d *decimator) interpolate(u, v int, p *Vec3) {
+	m := d.m
+	if !d.opt.Attributes {
+		return
+	}
+	pu, pv := m.Verts[u], m.Verts[v]
+	e := pv.Minus(pu)
+	t := float32(0)
+	if l := e.LengthSq(); l > 0 {
+		t = Max(0, Min(1, p.Minus(pu).Inner(e)/l))
+	}
+	if len(m.Normals) == len(m.Verts) {
+		Lerp3(&m.Normals[u], &m.Normals[v], t, &m.Normals[u])
+		normalizeNonZero(&m.Normals[u])
+	}
+	if len(m.UVs) == len(m.Verts) {
+		Lerp2(&m.UVs[u], &m.UVs[v], t, &m.UVs[u])
+	}
+	if len(m.Colors) == len(m.Verts) {
+		cu, cv := &m.Colors[u], &m.Colors[v]
+		cu.R += (cv.R - cu.R) * t
+		cu.G += (cv.G - cu.G) * t
+		cu.B += (cv.B - cu.B) * t
+		cu.A += (cv.A - cu.A) * t
+	}
+}
+
+// write the remaining faces and used vertices back to the mesh
+func (d *decimator) finish() {
+	m := d.m
+	newIdx := make([]int, len(m.Verts))
+	for f, face := range d.faces {
+		if d.faceAlive[f] {
+			for _, v := range face {
+				newIdx[v] = 1
+			}
+		}
+	}
+	var keep []int
+	for v, used := range newIdx {
+		if used != 0 {
+			newIdx[v] = len(keep)
+			keep = append(keep, v)
+		}
+	}
+	idx := make([]int, 0, 3*d.nFaces)
+	for f, face := range d.faces {
+		if d.faceAlive[f] {
+			idx = append(idx, newIdx[face[0]], newIdx[face[1]], newIdx[face[2]])
+		}
+	}
+	m.compactVerts(keep)
+	m.setTriIndices(idx)
+	m.keepTris(d.faceAlive)
+	if len(m.FaceNormals) > 0 {
+		m.ComputeFaceNormals()
+	}
+}
@@ -0,0 +1,153 @@
+package vec32
+
+import (
+	"testing"
+)
+
+// a flat n x n grid of quads in the xy-plane
+func newGridMesh(n int) *Mesh {
+	m := &Mesh{}
+	for y := 0; y <= n; y++ {
+		for x := 0; x <= n; x++ {
+			m.Verts = append(m.Verts, NewVec3(float32(x), float32(y), 0))
+			m.UVs = append(m.UVs, Vec2{float32(x) / float32(n), float32(y) / float32(n)})
+		}
+	}
+	for y := 0; y < n; y++ {
+		for x := 0; x < n; x++ {
+			i := uint32(y*(n+1) + x)
+			j := i + uint32(n+1)
+			m.Indices = append(m.Indices, i, i+1, j+1, i, j+1, j)
+		}
+	}
+	return m
+}
+
+func TestDecimateFlat(t *testing.T) {
+	m := newGridMesh(8)
+	m.ComputeFaceNormals()
+	if err := m.Decimate(nil); err != nil {
+		t.Fatalf("unexpected error %s", err.Error())
+	}
+	// only the boundary vertices are left: 32 vertices need 30 triangles
+	if len(m.Verts) != 32 || m.TriCount() != 30 || len(m.FaceNormals) != 30 {
+		t.Errorf("expected 32 vertices and 30 triangles, got %d and %d", len(m.Verts), m.TriCount())
+	}
+	for i := range m.Verts {
+		v := &m.Verts[i]
+		if v.Z != 0 || (v.X != 0 && v.X != 8 && v.Y != 0 && v.Y != 8) {
+			t.Errorf("vertex %s isn't on the boundary", v.String())
+		}
+		uv := Vec2{v.X / 8, v.Y / 8}
+		if !m.UVs[i].IsEqual(&uv) {
+			t.Errorf("wrong texture coordinate %s at %s", m.UVs[i].String(), v.String())
+		}
+	}
+	for _, n := range m.FaceNormals {
+		testVec3(t, "face normal", NewVec3(0, 0, 1), n)
+	}
+	if r := m.Validate(); len(r.Problems()) != 1 || len(r.Holes) != 1 {
+		t.Errorf("expected the outline as the only problem, got %v", r.Problems())
+	}
+}
+
+func TestDecimateBoundary(t *testing.T) {
+	m := newGridMesh(4)
+	m.Verts[12].Z = 1
+	opt := NewDecimateDefaultOptions()
+	opt.KeepBoundary = false
+	opt.MaxError = 0.01
+	if err := m.Decimate(opt); err != nil {
+		t.Fatalf("unexpected error %s", err.Error())
+	}
+	// the corners, the tip and at least its ring
+	if m.TriCount() < 8 || m.TriCount() >= 32 {
+		t.Errorf("unexpected number of triangles %d", m.TriCount())
+	}
+	bb := ORTHO_EMPTY
+	for i := range m.Verts {
+		Min3(&bb.P0, &m.Verts[i], &bb.P0)
+		Max3(&bb.P1, &m.Verts[i], &bb.P1)
+	}
+	if bb.P0 != NewVec3(0, 0, 0) || bb.P1 != NewVec3(4, 4, 1) {
+		t.Errorf("the shape has changed, got %s", bb.String())
+	}
+}
+
+func TestDecimateScale(t *testing.T) {
+	// the error bound scales with the squared size of the mesh
+	cnt := -1
+	for _, s := range []float32{1, 10, 0.1} {
+		m := newGridMesh(4)
+		m.Verts[12].Z = 1
+		for i := range m.Verts {
+			m.Verts[i] = m.Verts[i].Scaled(s)
+		}
+		opt := NewDecimateDefaultOptions()
+		opt.MaxError = 0.05 * s * s
+		if err := m.Decimate(opt); err != nil {
+			t.Fatalf("unexpected error %s", err.Error())
+		}
+		if cnt < 0 {
+			cnt = m.TriCount()
+			if cnt <= 16 || cnt >= 32 {
+				t.Errorf("the bound should stop the decimation, got %d triangles", cnt)
+			}
+		} else if m.TriCount() != cnt {
+			t.Errorf("scale %g: expected %d triangles, got %d", s, cnt, m.TriCount())
+		}
+	}
+}
+
+func TestDecimateTarget(t *testing.T) {
+	m, err := getMesh(t, 0, "people.sc.fsu.edu.helix.ply")
+	if err != nil {
+		return
+	}
+	before := m.Validate()
+	target := m.TriCount() / 4
+	opt := NewDecimateDefaultOptions()
+	opt.TargetTris = target
+	if err := m.Decimate(opt); err != nil {
+		t.Fatalf("unexpected error %s", err.Error())
+	}
+	if cnt := m.TriCount(); cnt > target || cnt < target-2 {
+		t.Errorf("expected %d triangles, got %d", target, cnt)
+	}
+	r := m.Validate()
+	if len(r.DegeneratedTris) != 0 || len(r.UnusedVerts) != 0 || len(r.InconsistentEdges) != 0 ||
+		len(r.NonManifoldEdges) != 0 || len(r.Holes) != len(before.Holes) {
+		t.Errorf("the result has problems %v", r.Problems())
+	}
+	if _, err := NewBVHTree(m, nil); err != nil {
+		t.Errorf("unexpected error %s", err.Error())
+	}
+}
+
+func TestDecimateClosed(t *testing.T) {
+	m, err := getMesh(t, 0, "two_cubes.ply")
+	if err != nil {
+		return
+	}
+	if err := m.Decimate(nil); err != nil {
+		t.Fatalf("unexpected error %s", err.Error())
+	}
+	// a tetrahedron is the smallest closed mesh
+	if m.TriCount() != 8 || len(m.Verts) != 8 {
+		t.Errorf("expected two tetrahedrons, got %d triangles", m.TriCount())
+	}
+	if r := m.Validate(); !r.IsValid() {
+		t.Errorf("the result should be valid, got %v", r.Problems())
+	}
+	h := newHalfEdgeMesh(t, m)
+	if _, n := h.Components(); n != 2 || !h.IsManifold() || !h.IsOriented() {
+		t.Errorf("expected two closed components, got %d", n)
+	}
+}
+
+func TestDecimateInvalid(t *testing.T) {
+	m := &Mesh{Verts: make([]Vec3, 2), Indices: []uint32{0, 1, 2}}
+	if err := m.Decimate(nil); err == nil {
+		t.Errorf("out of range indices should fail")
+	}
+}
@@ -0,0 +1,197 @@
+package vec32
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// A file format meshes can be loaded from and saved to
+//
+// Formats are found by the magic bytes at the start of a file or, if none
+// matches, by the extension of the file name.
+type MeshFormat interface {
+	// Short name of the format, like "ply"
+	Name() string
+	// Extensions of file names including the dot, like ".ply"
+	Extensions() []string
+	// Check the first bytes of a file, formats without magic return false
+	Match(head []byte) bool
+	Read(r io.Reader) (*Mesh, error)
+	Write(w io.Writer, m *Mesh) error
+}
+
+// number of bytes passed to MeshFormat.Match()
+const MESH_MAGIC_SIZE = 512
+
+var meshFormats struct {
+	sync.RWMutex
+	list []MeshFormat
+}
+
+// Add a format to the ones used by LoadMesh() and SaveMesh()
+//
+// Formats registered later take precedence over earlier ones, so the built
+// in formats can be replaced.
+func RegisterMeshFormat(f MeshFormat) {
+	meshFormats.Lock()
+	defer meshFormats.Unlock()
+	meshFormats.list = append(meshFormats.list, f)
+}
+
+// Get the format registered for the extension of a file name, nil if none
+//
+// A trailing .gz is ignored.
+func MeshFormatByName(path string) MeshFormat {
+	ext := strings.ToLower(filepath.Ext(strings.TrimSuffix(strings.ToLower(path), ".gz")))
+	meshFormats.RLock()
+	defer meshFormats.RUnlock()
+	for i := len(meshFormats.list) - 1; i >= 0; i-- {
+		for _, e := range meshFormats.list[i].Extensions() {
+			if strings.ToLower(e) == ext {
+				return meshFormats.list[i]
+			}
+		}
+	}
+	return nil
+}
+
+// Get the format matching the first bytes of a file, nil if none
+func MeshFormatByMagic(head []byte) MeshFormat {
+	meshFormats.RLock()
+	defer meshFormats.RUnlock()
+	for i := len(meshFormats.list) - 1; i >= 0; i-- {
+		if meshFormats.list[i].Match(head) {
+			return meshFormats.list[i]
+		}
+	}
+	return nil
+}
+
+// Load a mesh from a file of any registered format
+//
+// gzip-compressed files are decompressed transparently.
+func LoadMesh(path string) (*Mesh, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return readMesh(f, path)
+}
+
+// Read a mesh from a stream of any registered format with magic bytes
+func ReadMesh(r io.Reader) (*Mesh, error) {
+	return readMesh(r, "")
+}
+
+func readMesh(r io.Reader, path string) (*Mesh, error) {
+	size := readerSize(r)
+	rd := bufio.NewReader(r)
+	if head, _ := rd.Peek(2); bytes.Equal(head, []byte{0x1f, 0x8b}) {
+		gz, err := gzip.NewReader(rd)
+		if err != nil {
+			return nil, newErrorMesh(err.Error())
+		}
+		defer gz.Close()
+		rd = bufio.NewReader(gz)
+		size = -1
+	}
+	head, _ := rd.Peek(MESH_MAGIC_SIZE)
+	f := MeshFormatByMagic(head)
+	if f == nil {
+		f = MeshFormatByName(path)
+	}
+	if f == nil {
+		return nil, newErrorMesh("unknown mesh format")
+	}
+	if size >= 0 {
+		// formats like STL need the size, which the buffering hides
+		return f.Read(&sizedReader{rd: rd, n: size})
+	}
+	return f.Read(rd)
+}
+
+// a reader with a Len() of the bytes left, see readerSize()
+type sizedReader struct {
+	rd *bufio.Reader
+	n  int64
+}
+
+func (r *sizedReader) Read(p []byte) (int, error) {
+	n, err := r.rd.Read(p)
+	r.n -= int64(n)
+	return n, err
+}
+
+func (r *sizedReader) Len() int {
+	return int(r.n)
+}
+
+// Save a mesh to a file, the format is selected by the extension
+//
+// If the name ends with .gz, the file is gzip-compressed.
+func SaveMesh(path string, m *Mesh) (err error) {
+	format := MeshFormatByName(path)
+	if format == nil {
+		return newErrorMesh("unknown mesh format")
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if e := f.Close(); err == nil {
+			err = e
+		}
+	}()
+	if !strings.HasSuffix(strings.ToLower(path), ".gz") {
+		return format.Write(f, m)
+	}
+	gz := gzip.NewWriter(f)
+	if err = format.Write(gz, m); err != nil {
+		return err
+	}
+	return gz.Close()
+}
+
+type plyFormat struct{}
+
+func (plyFormat) Name() string         { return "ply" }
+func (plyFormat) Extensions() []string { return []string{".ply"} }
+func (plyFormat) Match(head []byte) bool {
+	return bytes.HasPrefix(head, []byte("ply\n")) || bytes.HasPrefix(head, []byte("ply\r"))
+}
+func (plyFormat) Read(r io.Reader) (*Mesh, error) { return ReadPLY(r) }
+func (plyFormat) Write(w io.Writer, m *Mesh) error {
+	return WritePLY(w, m, &PLYWriteOptions{Format: PLY_BINARY_LITTLE_ENDIAN})
+}
+
+type objFormat struct{}
+
+func (objFormat) Name() string                     { return "obj" }
+func (objFormat) Extensions() []string             { return []string{".obj"} }
+func (objFormat) Match(head []byte) bool           { return false }
+func (objFormat) Read(r io.Reader) (*Mesh, error)  { return ReadOBJ(r) }
+func (objFormat) Write(w io.Writer, m *Mesh) error { return WriteOBJ(w, m) }
+
+type stlFormat struct{}
+
+func (stlFormat) Name() string         { return "stl" }
+func (stlFormat) Extensions() []string { return []string{".stl"} }
+func (stlFormat) Match(head []byte) bool {
+	return isASCIISTL(bufio.NewReader(bytes.NewReader(head)), -1)
+}
+func (stlFormat) Read(r io.Reader) (*Mesh, error)  { return ReadSTL(r) }
+func (stlFormat) Write(w io.Writer, m *Mesh) error { return WriteSTL(w, m, nil) }
+
+func init() {
+	RegisterMeshFormat(plyFormat{})
+	RegisterMeshFormat(objFormat{})
+	RegisterMeshFormat(stlFormat{})
+}
@@ -0,0 +1,139 @@
+package vec32
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSaveLoadMesh(t *testing.T) {
+	m, _ := getMesh(t, 0, "paulbourke.net.sample1.ply")
+	if m == nil {
+		return
+	}
+	dir, err := os.MkdirTemp("", "vec32")
+	if err != nil {
+		t.Errorf("could not create temporary directory: %s", err.Error())
+		return
+	}
+	defer os.RemoveAll(dir)
+	var cases = []struct {
+		file   string
+		format string
+	}{
+		{"cube.ply", "ply"},
+		{"cube.PLY", "ply"},
+		{"cube.ply.gz", "ply"},
+		{"cube.obj", "obj"},
+		{"cube.obj.gz", "obj"},
+		{"cube.stl", "stl"},
+		{"cube.stl.GZ", "stl"},
+	}
+	for i, tc := range cases {
+		path := filepath.Join(dir, tc.file)
+		if f := MeshFormatByName(path); f == nil || f.Name() != tc.format {
+			t.Errorf("tc %d: expected format %s", i, tc.format)
+		}
+		if err = SaveMesh(path, m); err != nil {
+			t.Errorf("tc %d: error on saving: %s", i, err.Error())
+			continue
+		}
+		m2, err := LoadMesh(path)
+		if err != nil {
+			t.Errorf("tc %d: error on loading: %s", i, err.Error())
+			continue
+		}
+		testMeshGeometry(t, i, m, m2)
+	}
+
+	// binary STL with the keywords of ASCII files in the header
+	path := filepath.Join(dir, "solid.stl")
+	if err = WriteSTL(mustCreate(t, path), m, &STLWriteOptions{Binary: true, Name: "solid x facet"}); err == nil {
+		m2, err := LoadMesh(path)
+		if err != nil {
+			t.Errorf("error on loading binary STL: %s", err.Error())
+		} else {
+			testMeshGeometry(t, 0, m, m2)
+		}
+	}
+
+	// the magic wins over the extension
+	path = filepath.Join(dir, "cube.obj")
+	if err = WritePLY(mustCreate(t, path), m, nil); err == nil {
+		m2, err := LoadMesh(path)
+		if err != nil {
+			t.Errorf("error on loading PLY named obj: %s", err.Error())
+		} else {
+			testMeshEqual(t, 0, m, m2)
+		}
+	}
+
+	if err = SaveMesh(filepath.Join(dir, "cube.xyz"), m); err == nil || err.Error() != "unknown mesh format" {
+		t.Errorf("expected error on unknown format, got %v", err)
+	}
+	if _, err = LoadMesh(filepath.Join(dir, "missing.ply")); err == nil {
+		t.Errorf("expected error on missing file")
+	}
+	if _, err = ReadMesh(bytes.NewReader([]byte("foo"))); err == nil || err.Error() != "unknown mesh format" {
+		t.Errorf("expected error on unknown stream, got %v", err)
+	}
+}
+
+// a format storing nothing but the number of vertices
+type testFormat struct{}
+
+func (testFormat) Name() string           { return "test" }
+func (testFormat) Extensions() []string   { return []string{".test", ".ply"} }
+func (testFormat) Match(head []byte) bool { return bytes.HasPrefix(head, []byte("TEST")) }
+func (testFormat) Read(r io.Reader) (*Mesh, error) {
+	var n [1]byte
+	if _, err := io.ReadFull(r, make([]byte, 4)); err != nil {
+		return nil, err
+	}
+	if _, err := io.ReadFull(r, n[:]); err != nil {
+		return nil, err
+	}
+	return &Mesh{Verts: make([]Vec3, n[0])}, nil
+}
+func (testFormat) Write(w io.Writer, m *Mesh) error {
+	_, err := w.Write([]byte{'T', 'E', 'S', 'T', byte(len(m.Verts))})
+	return err
+}
+
+func TestRegisterMeshFormat(t *testing.T) {
+	meshFormats.Lock()
+	saved := meshFormats.list
+	meshFormats.list = append([]MeshFormat(nil), saved...)
+	meshFormats.Unlock()
+	defer func() {
+		meshFormats.Lock()
+		meshFormats.list = saved
+		meshFormats.Unlock()
+	}()
+
+	RegisterMeshFormat(testFormat{})
+	if f := MeshFormatByName("foo.ply"); f == nil || f.Name() != "test" {
+		t.Errorf("registered format should replace ply")
+	}
+	m, err := ReadMesh(bytes.NewReader([]byte("TEST\x05")))
+	if err != nil || len(m.Verts) != 5 {
+		t.Errorf("failed to read by magic: %v", err)
+	}
+	// other formats are still found by magic
+	m, err = LoadMesh("test/ply/paulbourke.net.sample1.ply")
+	if err != nil || len(m.Verts) != 8 {
+		t.Errorf("failed to read PLY: %v", err)
+	}
+}
+
+func mustCreate(t *testing.T, path string) io.Writer {
+	f, err := os.Create(path)
+	if err != nil {
+		t.Errorf("could not create %s: %s", path, err.Error())
+		return io.Discard
+	}
+	t.Cleanup(func() { f.Close() })
+	return f
+}
@@ -0,0 +1,442 @@
+package vec32
+
+import (
+	"sort"
+	"strconv"
+)
+
+// Half-edge structure for topology queries on a triangle mesh
+//
+// The half-edges are implicit: half-edge e = 3*face+k goes from corner k
+// to the next corner of the face. Edges with more than two faces are
+// non-manifold, two faces running along an edge in the same direction are
+// oriented inconsistently. Neither has a twin.
+type HalfEdgeMesh struct {
+	m       *Mesh
+	indices []uint32
+	nVerts  int
+	// the other half-edge of edges with exactly two faces, else -1
+	mate []int
+	// number of faces at the edge of each half-edge
+	faces []int
+	// outgoing half-edges of vertex v are out[outStart[v]:outStart[v+1]]
+	outStart []int
+	out      []int
+}
+
+// Build the half-edge structure of a mesh
+//
+// The triangles are copied, later changes of them aren't seen. The mesh is
+// referenced for Mesh() and ToMesh(). Triangles using a vertex more than
+// once are an error.
+func NewHalfEdgeMesh(m *Mesh) (*HalfEdgeMesh, error) {
+	idx, err := m.triIndexList()
+	if err != nil {
+		return nil, err
+	}
+	for i := 0; i < len(idx); i += 3 {
+		if idx[i] == idx[i+1] || idx[i+1] == idx[i+2] || idx[i] == idx[i+2] {
+			return nil, newErrorMesh("triangle " + strconv.Itoa(i/3) + " uses a vertex twice")
+		}
+	}
+	h := &HalfEdgeMesh{m: m, nVerts: len(m.Verts), indices: make([]uint32, len(idx))}
+	for i, v := range idx {
+		h.indices[i] = uint32(v)
+	}
+	h.build()
+	return h, nil
+}
+
+func (h *HalfEdgeMesh) build() {
+	idx := make([]int, len(h.indices))
+	for i, v := range h.indices {
+		idx[i] = int(v)
+	}
+	// corners of a vertex are the half-edges starting at it
+	h.outStart, h.out = vertCorners(idx, h.nVerts)
+	h.mate = make([]int, len(idx))
+	h.faces = make([]int, len(idx))
+	for e := range h.mate {
+		h.mate[e] = -1
+		a, b := h.From(e), h.To(e)
+		for _, o := range h.Outgoing(b) {
+			if h.To(o) == a {
+				h.faces[e] += 1
+				h.mate[e] = o
+			}
+		}
+		for _, o := range h.Outgoing(a) {
+			if h.To(o) == b {
+				h.faces[e] += 1
+				if o != e {
+					h.mate[e] = o
+				}
+			}
+		}
+		if h.faces[e] != 2 {
+			h.mate[e] = -1
+		}
+	}
+}
+
+// Get the mesh the structure was built for
+func (h *HalfEdgeMesh) Mesh() *Mesh {
+	return h.m
+}
+
+// Get a new mesh with the triangles of the half-edge structure
+//
+// Vertices and attributes are copied from the current state of the mesh
+// the structure was built for, face normals are recomputed if it has them.
+func (h *HalfEdgeMesh) ToMesh() *Mesh {
+	src := h.m
+	m := &Mesh{
+		Verts:        append([]Vec3(nil), src.Verts...),
+		Indices:      append([]uint32(nil), h.indices...),
+		Normals:      append([]Vec3(nil), src.Normals...),
+		UVs:          append([]Vec2(nil), src.UVs...),
+		Colors:       append([]Color(nil), src.Colors...),
+		Groups:       append([]MeshGroup(nil), src.Groups...),
+		MaterialLibs: append([]string(nil), src.MaterialLibs...),
+	}
+	if len(src.FaceNormals) > 0 {
+		m.ComputeFaceNormals()
+	}
+	return m
+}
+
+// Get the number of vertices
+func (h *HalfEdgeMesh) NumVerts() int { return h.nVerts }
+
+// Get the number of triangles
+func (h *HalfEdgeMesh) NumFaces() int { return len(h.indices) / 3 }
+
+// Get the number of half-edges, 3 per triangle
+func (h *HalfEdgeMesh) NumHalfEdges() int { return len(h.indices) }
+
+// Get the next half-edge of the same face
+func (h *HalfEdgeMesh) Next(e int) int {
+	if e%3 == 2 {
+		return e - 2
+	}
+	return e + 1
+}
+
+// Get the previous half-edge of the same face
+func (h *HalfEdgeMesh) Prev(e int) int {
+	if e%3 == 0 {
+		return e + 2
+	}
+	return e - 1
+}
+
+// Get the face of a half-edge
+func (h *HalfEdgeMesh) Face(e int) int { return e / 3 }
+
+// Get the vertex the half-edge starts at
+func (h *HalfEdgeMesh) From(e int) int { return int(h.indices[e]) }
+
+// Get the vertex the half-edge ends at
+func (h *HalfEdgeMesh) To(e int) int { return int(h.indices[h.Next(e)]) }
+
+// Get the opposite half-edge, -1 on boundary, non-manifold and
+// inconsistently oriented edges
+func (h *HalfEdgeMesh) Twin(e int) int {
+	if m := h.mate[e]; m >= 0 && h.From(m) == h.To(e) {
+		return m
+	}
+	return -1
+}
+
+// Get the half-edges starting at vertex v
+//
+// The slice belongs to the structure and must not be changed.
+func (h *HalfEdgeMesh) Outgoing(v int) []int {
+	return h.out[h.outStart[v]:h.outStart[v+1]]
+}
+
+// Get the number of faces sharing the edge of half-edge e
+func (h *HalfEdgeMesh) EdgeFaceCount(e int) int {
+	return h.faces[e]
+}
+
+// Check if the edge of half-edge e has a single face
+func (h *HalfEdgeMesh) IsBoundary(e int) bool {
+	return h.faces[e] == 1
+}
+
+// Get the vertices connected to v by an edge, sorted
+//
+// The result is appended to ring[:0].
+func (h *HalfEdgeMesh) OneRing(v int, ring []int) []int {
+	ring = ring[:0]
+	for _, e := range h.Outgoing(v) {
+		ring = append(ring, h.To(e), h.From(h.Prev(e)))
+	}
+	return sortUnique(ring)
+}
+
+// Get the faces using vertex v, sorted
+//
+// The result is appended to faces[:0].
+func (h *HalfEdgeMesh) VertFaces(v int, faces []int) []int {
+	faces = faces[:0]
+	for _, e := range h.Outgoing(v) {
+		faces = append(faces, h.Face(e))
+	}
+	return sortUnique(faces)
+}
+
+// Get the faces sharing the edge between the vertices a and b, sorted
+//
+// The result is appended to faces[:0], it is empty if there is no such
+// edge.
+func (h *HalfEdgeMesh) EdgeFaces(a, b int, faces []int) []int {
+	faces = faces[:0]
+	for _, e := range h.Outgoing(a) {
+		if h.To(e) == b {
+			faces = append(faces, h.Face(e))
+		}
+	}
+	for _, e := range h.Outgoing(b) {
+		if h.To(e) == a {
+			faces = append(faces, h.Face(e))
+		}
+	}
+	return sortUnique(faces)
+}
+
+// Get the half-edges of edges with a single face
+func (h *HalfEdgeMesh) BoundaryEdges() []int {
+	var edges []int
+	for e, n := range h.faces {
+		if n == 1 {
+			edges = append(edges, e)
+		}
+	}
+	return edges
+}
+
+// Get edges shared by more than two faces, one half-edge per edge
+func (h *HalfEdgeMesh) NonManifoldEdges() []int {
+	var edges []int
+	for e, n := range h.faces {
+		if n > 2 && h.isFirstOfEdge(e) {
+			edges = append(edges, e)
+		}
+	}
+	return edges
+}
+
+// Get edges whose two faces run along it in the same direction, one
+// half-edge per edge
+func (h *HalfEdgeMesh) InconsistentEdges() []int {
+	var edges []int
+	for e, m := range h.mate {
+		if m > e && h.From(m) == h.From(e) {
+			edges = append(edges, e)
+		}
+	}
+	return edges
+}
+
+// Get vertices whose faces form more than one fan (like the tip of two
+// cones touching each other)
+func (h *HalfEdgeMesh) NonManifoldVerts() []int {
+	var verts []int
+	var seen []bool
+	var stack []int
+	for v := 0; v < h.nVerts; v++ {
+		out := h.Outgoing(v)
+		if len(out) < 2 {
+			continue
+		}
+		seen = append(seen[:0], make([]bool, len(out))...)
+		// walk around the vertex from the first corner over edges with
+		// two faces, the fan is complete if all corners are reached
+		seen[0] = true
+		stack = append(stack[:0], out[0])
+		cnt := 1
+		for len(stack) > 0 {
+			e := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			for _, m := range [2]int{h.mate[e], h.mate[h.Prev(e)]} {
+				if m < 0 {
+					continue
+				}
+				// the corner of the other face at v
+				c := m
+				if h.From(m) != v {
+					c = h.Next(m)
+				}
+				for i, o := range out {
+					if o == c && !seen[i] {
+						seen[i] = true
+						cnt += 1
+						stack = append(stack, o)
+					}
+				}
+			}
+		}
+		if cnt < len(out) {
+			verts = append(verts, v)
+		}
+	}
+	return verts
+}
+
+// Check if every edge has at most two faces and every vertex a single fan
+func (h *HalfEdgeMesh) IsManifold() bool {
+	return len(h.NonManifoldEdges()) == 0 && len(h.NonManifoldVerts()) == 0
+}
+
+// Check if neighboring faces are oriented the same way
+func (h *HalfEdgeMesh) IsOriented() bool {
+	return len(h.InconsistentEdges()) == 0
+}
+
+// Get the boundary loops as lists of vertices
+//
+// The loops follow the direction of the boundary half-edges.
+func (h *HalfEdgeMesh) BoundaryLoops() [][]int {
+	var loops [][]int
+	visited := make([]bool, len(h.faces))
+	for start, n := range h.faces {
+		if n != 1 || visited[start] {
+			continue
+		}
+		var loop []int
+		for e := start; e >= 0; {
+			visited[e] = true
+			loop = append(loop, h.From(e))
+			next := -1
+			for _, o := range h.Outgoing(h.To(e)) {
+				if h.faces[o] == 1 && !visited[o] {
+					next = o
+					break
+				}
+			}
+			e = next
+		}
+		loops = append(loops, loop)
+	}
+	return loops
+}
+
+// Get the connected components of the faces
+//
+// Faces sharing an edge belong to the same component. Returns the
+// component of each face and the number of components.
+func (h *HalfEdgeMesh) Components() (comp []int, n int) {
+	comp = make([]int, h.NumFaces())
+	for i := range comp {
+		comp[i] = -1
+	}
+	var stack, faces []int
+	for f := range comp {
+		if comp[f] >= 0 {
+			continue
+		}
+		comp[f] = n
+		stack = append(stack[:0], f)
+		for len(stack) > 0 {
+			cur := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			for e := 3 * cur; e < 3*cur+3; e++ {
+				faces = h.EdgeFaces(h.From(e), h.To(e), faces)
+				for _, g := range faces {
+					if comp[g] < 0 {
+						comp[g] = n
+						stack = append(stack, g)
+					}
+				}
+			}
+		}
+		n += 1
+	}
+	return comp, n
+}
+
+// Flip faces to make the orientation consistent
+//
+// Each component keeps the orientation of the majority of its faces.
+// Non-orientable components (like a Moebius strip) stay inconsistent.
+// Returns the number of flipped faces.
+func (h *HalfEdgeMesh) Orient() int {
+	nFaces := h.NumFaces()
+	flip := make([]bool, nFaces)
+	done := make([]bool, nFaces)
+	var stack, members []int
+	flipped := 0
+	for f := 0; f < nFaces; f++ {
+		if done[f] {
+			continue
+		}
+		done[f] = true
+		stack = append(stack[:0], f)
+		members = append(members[:0], f)
+		nFlip := 0
+		for len(stack) > 0 {
+			cur := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			for e := 3 * cur; e < 3*cur+3; e++ {
+				m := h.mate[e]
+				if m < 0 || done[h.Face(m)] {
+					continue
+				}
+				g := h.Face(m)
+				// same direction along the edge needs a flip
+				flip[g] = flip[cur] != (h.From(m) == h.From(e))
+				if flip[g] {
+					nFlip += 1
+				}
+				done[g] = true
+				stack = append(stack, g)
+				members = append(members, g)
+			}
+		}
+		invert := 2*nFlip > len(members)
+		for _, g := range members {
+			if flip[g] != invert {
+				h.indices[3*g+1], h.indices[3*g+2] = h.indices[3*g+2], h.indices[3*g+1]
+				flipped += 1
+			}
+		}
+	}
+	if flipped > 0 {
+		h.build()
+	}
+	return flipped
+}
+
+// the first half-edge of an edge, in the order of the outgoing lists
+func (h *HalfEdgeMesh) isFirstOfEdge(e int) bool {
+	a, b := h.From(e), h.To(e)
+	for _, o := range h.Outgoing(a) {
+		if h.To(o) == b {
+			if o != e {
+				return false
+			}
+			break
+		}
+	}
+	for _, o := range h.Outgoing(b) {
+		if h.To(o) == a && o < e {
+			return false
+		}
+	}
+	return true
+}
+
+// sort and remove duplicates
+func sortUnique(v []int) []int {
+	sort.Ints(v)
+	n := 0
+	for i := range v {
+		if i == 0 || v[i] != v[n-1] {
+			v[n] = v[i]
+			n += 1
+		}
+	}
+	return v[:n]
+}
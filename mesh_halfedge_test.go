@@ -0,0 +1,153 @@
+package vec32
+
+import (
+	"reflect"
+	"testing"
+)
+
+func newIndexedMesh(nVerts int, indices ...uint32) *Mesh {
+	m := &Mesh{Verts: make([]Vec3, nVerts), Indices: indices}
+	for i := range m.Verts {
+		m.Verts[i] = NewVec3(float32(i), float32(i*i%7), float32(i%3))
+	}
+	return m
+}
+
+func newHalfEdgeMesh(t *testing.T, m *Mesh) *HalfEdgeMesh {
+	h, err := NewHalfEdgeMesh(m)
+	if err != nil {
+		t.Fatalf("unexpected error %s", err.Error())
+	}
+	return h
+}
+
+func TestHalfEdgeTetrahedron(t *testing.T) {
+	h := newHalfEdgeMesh(t, newIndexedMesh(4, 0, 2, 1, 0, 1, 3, 1, 2, 3, 0, 3, 2))
+	if !h.IsManifold() || !h.IsOriented() || len(h.BoundaryEdges()) != 0 {
+		t.Errorf("a tetrahedron is closed, manifold and oriented")
+	}
+	for e := 0; e < h.NumHalfEdges(); e++ {
+		tw := h.Twin(e)
+		if tw < 0 || h.Twin(tw) != e || h.From(tw) != h.To(e) || h.To(tw) != h.From(e) {
+			t.Errorf("wrong twin %d of half-edge %d", tw, e)
+		}
+		if h.Next(h.Next(h.Next(e))) != e || h.Prev(h.Next(e)) != e {
+			t.Errorf("Next() and Prev() of %d don't form a cycle", e)
+		}
+	}
+	if ring := h.OneRing(0, nil); !reflect.DeepEqual(ring, []int{1, 2, 3}) {
+		t.Errorf("OneRing(0) is wrong - got %v", ring)
+	}
+	if faces := h.VertFaces(3, nil); !reflect.DeepEqual(faces, []int{1, 2, 3}) {
+		t.Errorf("VertFaces(3) is wrong - got %v", faces)
+	}
+	if faces := h.EdgeFaces(1, 0, nil); !reflect.DeepEqual(faces, []int{0, 1}) {
+		t.Errorf("EdgeFaces(1, 0) is wrong - got %v", faces)
+	}
+	if _, n := h.Components(); n != 1 {
+		t.Errorf("expected 1 component, got %d", n)
+	}
+}
+
+func TestHalfEdgeBoundary(t *testing.T) {
+	// a quad of two triangles
+	h := newHalfEdgeMesh(t, newIndexedMesh(4, 0, 1, 2, 0, 2, 3))
+	if len(h.BoundaryEdges()) != 4 || !h.IsManifold() || !h.IsOriented() {
+		t.Errorf("expected a manifold with 4 boundary edges, got %v", h.BoundaryEdges())
+	}
+	loops := h.BoundaryLoops()
+	if !reflect.DeepEqual(loops, [][]int{{0, 1, 2, 3}}) {
+		t.Errorf("BoundaryLoops() is wrong - got %v", loops)
+	}
+	if faces := h.EdgeFaces(1, 3, nil); len(faces) != 0 {
+		t.Errorf("there is no edge between 1 and 3, got faces %v", faces)
+	}
+	if ring := h.OneRing(1, nil); !reflect.DeepEqual(ring, []int{0, 2}) {
+		t.Errorf("OneRing(1) is wrong - got %v", ring)
+	}
+}
+
+func TestHalfEdgeNonManifold(t *testing.T) {
+	// three triangles sharing the edge 0-1
+	h := newHalfEdgeMesh(t, newIndexedMesh(5, 0, 1, 2, 1, 0, 3, 0, 1, 4))
+	edges := h.NonManifoldEdges()
+	if len(edges) != 1 || h.EdgeFaceCount(edges[0]) != 3 || h.IsManifold() {
+		t.Errorf("expected one non-manifold edge, got %v", edges)
+	}
+	if h.Twin(0) != -1 {
+		t.Errorf("non-manifold edges have no twin")
+	}
+
+	// two triangles touching at vertex 0
+	h = newHalfEdgeMesh(t, newIndexedMesh(5, 0, 1, 2, 0, 3, 4))
+	if verts := h.NonManifoldVerts(); !reflect.DeepEqual(verts, []int{0}) || h.IsManifold() {
+		t.Errorf("expected vertex 0 to be non-manifold, got %v", verts)
+	}
+	if comp, n := h.Components(); n != 2 || comp[0] == comp[1] {
+		t.Errorf("expected 2 components, got %v", comp)
+	}
+
+	// a closed fan isn't split
+	h = newHalfEdgeMesh(t, newIndexedMesh(5, 0, 1, 2, 0, 2, 3, 0, 3, 4, 0, 4, 1))
+	if verts := h.NonManifoldVerts(); len(verts) != 0 {
+		t.Errorf("expected no non-manifold vertices, got %v", verts)
+	}
+}
+
+func TestHalfEdgeOrient(t *testing.T) {
+	// the first triangle is oriented the other way than the rest
+	m := newIndexedMesh(5, 0, 1, 2, 0, 3, 2, 0, 4, 3)
+	m.ComputeFaceNormals()
+	h := newHalfEdgeMesh(t, m)
+	if h.IsOriented() || len(h.InconsistentEdges()) != 1 {
+		t.Errorf("expected one inconsistent edge, got %v", h.InconsistentEdges())
+	}
+	if n := h.Orient(); n != 1 {
+		t.Errorf("expected to flip the first triangle only, flipped %d", n)
+	}
+	if !h.IsOriented() {
+		t.Errorf("orientation should be consistent after Orient()")
+	}
+	if h.Mesh() != m {
+		t.Errorf("Mesh() should return the mesh the structure was built for")
+	}
+	res := h.ToMesh()
+	if !reflect.DeepEqual(res.Indices, []uint32{0, 2, 1, 0, 3, 2, 0, 4, 3}) {
+		t.Errorf("wrong indices after Orient() - got %v", res.Indices)
+	}
+	if res.TriCount() != 3 || len(res.FaceNormals) != 3 || len(res.Verts) != 5 {
+		t.Errorf("ToMesh() is incomplete")
+	}
+	if !reflect.DeepEqual(m.Indices, []uint32{0, 1, 2, 0, 3, 2, 0, 4, 3}) {
+		t.Errorf("the source mesh shouldn't be changed")
+	}
+}
+
+func TestHalfEdgeCubes(t *testing.T) {
+	m, err := getMesh(t, 0, "two_cubes.ply")
+	if err != nil {
+		return
+	}
+	h := newHalfEdgeMesh(t, m)
+	if !h.IsManifold() || !h.IsOriented() || len(h.BoundaryEdges()) != 0 {
+		t.Errorf("the cubes should be closed, oriented manifolds")
+	}
+	comp, n := h.Components()
+	if n != 2 || comp[0] == comp[len(comp)-1] {
+		t.Errorf("expected 2 components, got %v", comp)
+	}
+	if ring := h.OneRing(0, nil); len(ring) < 3 {
+		t.Errorf("a corner of a cube has at least 3 neighbors, got %v", ring)
+	}
+	if _, err := NewHalfEdgeMesh(&Mesh{Verts: make([]Vec3, 2), Indices: []uint32{0, 1, 2}}); err == nil {
+		t.Errorf("out of range indices should fail")
+	}
+}
+
+func TestHalfEdgeDegenerated(t *testing.T) {
+	for _, idx := range [][]uint32{{0, 0, 1}, {0, 1, 2, 0, 1, 1}, {1, 2, 1}} {
+		if _, err := NewHalfEdgeMesh(newIndexedMesh(3, idx...)); err == nil {
+			t.Errorf("a triangle using a vertex twice should fail - %v", idx)
+		}
+	}
+}
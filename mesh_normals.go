@@ -0,0 +1,205 @@
+package vec32
+
+// Weighting of the face normals for vertex normals
+const (
+	// by the area of the triangles
+	NORMAL_WEIGHT_AREA = iota
+	// by the angle of the triangles at the vertex
+	NORMAL_WEIGHT_ANGLE
+)
+
+// options for computing vertex normals
+//
+// Faces meeting at a vertex with normals more than CreaseAngle (radians)
+// apart are shaded separately, the vertex is split along these hard edges.
+// 0 disables the splitting.
+type NormalOptions struct {
+	Weighting   uint
+	CreaseAngle float32
+}
+
+func NewNormalDefaultOptions() *NormalOptions {
+	return &NormalOptions{
+		Weighting:   NORMAL_WEIGHT_AREA,
+		CreaseAngle: 0,
+	}
+}
+
+// Compute the unit normals of all triangles and store them in FaceNormals
+//
+// Degenerated triangles and triangles with indices out of range get a
+// zero normal.
+func (m *Mesh) ComputeFaceNormals() {
+	n := m.TriCount()
+	if len(m.FaceNormals) != n {
+		m.FaceNormals = make([]Vec3, n)
+	}
+	for i := 0; i < n; i++ {
+		if len(m.Indices) > 0 {
+			if _, _, _, ok := m.TriVerts(i); !ok {
+				m.FaceNormals[i] = Vec3{}
+				continue
+			}
+		}
+		tri := m.Tri(i)
+		facetNormal(&tri, &m.FaceNormals[i])
+	}
+}
+
+// Compute the vertex normals and store them in Normals
+//
+// FaceNormals are updated as well. With a crease angle, vertices on hard
+// edges are duplicated (including their UVs and colors), which appends to
+// Verts and recreates Indices. Unused vertices get a zero normal.
+func (m *Mesh) ComputeNormals(opt *NormalOptions) error {
+	if opt == nil {
+		opt = NewNormalDefaultOptions()
+	}
+	idx, err := m.triIndexList()
+	if err != nil {
+		return err
+	}
+	m.ComputeFaceNormals()
+	weights := make([]float32, len(idx))
+	for i := 0; i < len(idx)/3; i++ {
+		tri := m.Tri(i)
+		cornerWeights(&tri, opt.Weighting, weights[3*i:3*i+3])
+	}
+	if opt.CreaseAngle <= 0 {
+		m.Normals = make([]Vec3, len(m.Verts))
+		for c, v := range idx {
+			n := m.FaceNormals[c/3].Scaled(weights[c])
+			m.Normals[v] = m.Normals[v].Plus(n)
+		}
+		for i := range m.Normals {
+			normalizeNonZero(&m.Normals[i])
+		}
+		return nil
+	}
+	m.splitCreases(idx, weights, Cos(opt.CreaseAngle))
+	return nil
+}
+
+// the normals of the corners of a vertex only include the faces within the
+// crease angle, vertices get a copy for each distinct corner normal
+func (m *Mesh) splitCreases(idx []int, weights []float32, cosCrease float32) {
+	nVerts := len(m.Verts)
+	start, corners := vertCorners(idx, nVerts)
+	cornerNormals := make([]Vec3, len(idx))
+	for v := 0; v < nVerts; v++ {
+		around := corners[start[v]:start[v+1]]
+		for _, c := range around {
+			fn := &m.FaceNormals[c/3]
+			// degenerated faces use all neighbors
+			degenerated := fn.LengthSq() == 0
+			var n Vec3
+			for _, c2 := range around {
+				fn2 := &m.FaceNormals[c2/3]
+				if degenerated || fn.Inner(*fn2) >= cosCrease {
+					n = n.Plus(fn2.Scaled(weights[c2]))
+				}
+			}
+			normalizeNonZero(&n)
+			cornerNormals[c] = n
+		}
+	}
+
+	m.Normals = make([]Vec3, nVerts)
+	var copies []int
+	var normals []Vec3
+	for v := 0; v < nVerts; v++ {
+		normals = normals[:0]
+		copies = copies[:0]
+		for _, c := range corners[start[v]:start[v+1]] {
+			j := 0
+			for j < len(normals) && !normals[j].IsEqual(&cornerNormals[c]) {
+				j++
+			}
+			if j == len(normals) {
+				normals = append(normals, cornerNormals[c])
+				if j == 0 {
+					copies = append(copies, v)
+					m.Normals[v] = cornerNormals[c]
+				} else {
+					copies = append(copies, m.copyVert(v))
+					m.Normals = append(m.Normals, cornerNormals[c])
+				}
+			}
+			idx[c] = copies[j]
+		}
+	}
+	m.setTriIndices(idx)
+}
+
+// append a copy of vertex i including its attributes, except the normal
+func (m *Mesh) copyVert(i int) int {
+	m.Verts = append(m.Verts, m.Verts[i])
+	if len(m.UVs) > 0 {
+		m.UVs = append(m.UVs, m.UVs[i])
+	}
+	if len(m.Colors) > 0 {
+		m.Colors = append(m.Colors, m.Colors[i])
+	}
+	return len(m.Verts) - 1
+}
+
+// the weights of the face normal at the corners P1, P2 and P3
+func cornerWeights(tri *Triangle, weighting uint, w []float32) {
+	if weighting == NORMAL_WEIGHT_ANGLE {
+		e12, e13, e23 := tri.P2.Minus(*tri.P1), tri.P3.Minus(*tri.P1), tri.P3.Minus(*tri.P2)
+		e21, e31, e32 := e12.Negated(), e13.Negated(), e23.Negated()
+		w[0] = e12.Angle(&e13)
+		w[1] = e23.Angle(&e21)
+		w[2] = e31.Angle(&e32)
+		return
+	}
+	c := tri.P2.Minus(*tri.P1).Crossed(tri.P3.Minus(*tri.P1))
+	area := c.Length() / 2
+	w[0], w[1], w[2] = area, area, area
+}
+
+// the corners (3*tri + 0..2) using vertex v are corners[start[v]:start[v+1]]
+func vertCorners(idx []int, nVerts int) (start, corners []int) {
+	start = make([]int, nVerts+1)
+	for _, v := range idx {
+		start[v+1] += 1
+	}
+	for v := 0; v < nVerts; v++ {
+		start[v+1] += start[v]
+	}
+	pos := append([]int(nil), start[:nVerts]...)
+	corners = make([]int, len(idx))
+	for c, v := range idx {
+		corners[pos[v]] = c
+		pos[v] += 1
+	}
+	return start, corners
+}
+
+func normalizeNonZero(v *Vec3) {
+	if l := v.Length(); l > 0 {
+		*v = v.Scaled(1 / l)
+	}
+}
+
+// the vertex indices of all triangles, 3 per triangle
+func (m *Mesh) triIndexList() ([]int, error) {
+	if err := m.checkTris(); err != nil {
+		return nil, err
+	}
+	idx := make([]int, 3*m.TriCount())
+	for i := 0; i < len(idx)/3; i++ {
+		idx[3*i], idx[3*i+1], idx[3*i+2], _ = m.TriVerts(i)
+	}
+	return idx, nil
+}
+
+// set Indices to the vertices of idx, 3 per triangle
+func (m *Mesh) setTriIndices(idx []int) {
+	m.Quads = nil
+	m.Tris = nil
+	m.Indices = make([]uint32, len(idx))
+	for i, v := range idx {
+		m.Indices[i] = uint32(v)
+	}
+}
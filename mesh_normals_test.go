@@ -0,0 +1,131 @@
+package vec32
+
+import (
+	"testing"
+)
+
+func TestComputeFaceNormals(t *testing.T) {
+	m := &Mesh{Verts: []Vec3{NewVec3(0, 0, 0), NewVec3(2, 0, 0), NewVec3(0, 2, 0), NewVec3(4, 0, 0)}}
+	m.Tris = []Triangle{
+		{&m.Verts[0], &m.Verts[1], &m.Verts[2]},
+		{&m.Verts[0], &m.Verts[2], &m.Verts[1]},
+		{&m.Verts[0], &m.Verts[1], &m.Verts[3]},
+	}
+	m.ComputeFaceNormals()
+	testVec3(t, "FaceNormals[0]", NewVec3(0, 0, 1), m.FaceNormals[0])
+	testVec3(t, "FaceNormals[1]", NewVec3(0, 0, -1), m.FaceNormals[1])
+	testVec3(t, "degenerated FaceNormals[2]", NewVec3(0, 0, 0), m.FaceNormals[2])
+
+	invalid := &Mesh{Verts: m.Verts[:3], Indices: []uint32{0, 1, 2, 0, 1, 3}}
+	invalid.ComputeFaceNormals()
+	testVec3(t, "FaceNormals[0]", NewVec3(0, 0, 1), invalid.FaceNormals[0])
+	testVec3(t, "invalid FaceNormals[1]", NewVec3(0, 0, 0), invalid.FaceNormals[1])
+
+	var hit Intersection
+	var n Vec3
+	if !m.InterpolateNormal(1, &hit, &n) {
+		t.Errorf("InterpolateNormal() should use the face normals")
+	}
+	testVec3(t, "InterpolateNormal()", NewVec3(0, 0, -1), n)
+}
+
+func TestComputeNormalsSmooth(t *testing.T) {
+	m, err := getMesh(t, 0, "two_cubes.ply")
+	if err != nil {
+		return
+	}
+	opt := NewNormalDefaultOptions()
+	opt.Weighting = NORMAL_WEIGHT_ANGLE
+	if err = m.ComputeNormals(opt); err != nil {
+		t.Errorf("unexpected error %s", err.Error())
+		return
+	}
+	if len(m.Verts) != 16 || len(m.Normals) != 16 || len(m.FaceNormals) != m.TriCount() {
+		t.Errorf("wrong sizes - %d verts, %d normals, %d face normals",
+			len(m.Verts), len(m.Normals), len(m.FaceNormals))
+		return
+	}
+	// each corner sees three faces at 90 degrees
+	for i := range m.Verts {
+		cube := float32(0)
+		if i >= 8 {
+			cube = 3
+		}
+		d := m.Verts[i].Minus(NewVec3(cube+0.5, 0.5, 0.5)).Normalized()
+		if !AlmostEqual(Abs(m.Normals[i].Inner(d)), 1) {
+			t.Errorf("normal %d is wrong - expected +-%s got %s", i, d.String(), m.Normals[i].String())
+		}
+	}
+}
+
+func TestComputeNormalsCrease(t *testing.T) {
+	m, err := getMesh(t, 0, "two_cubes.ply")
+	if err != nil {
+		return
+	}
+	if err = m.ComputeNormals(&NormalOptions{CreaseAngle: 0.5}); err != nil {
+		t.Errorf("unexpected error %s", err.Error())
+		return
+	}
+	// every corner of the cubes is split into one vertex per side
+	if len(m.Verts) != 48 || len(m.Normals) != 48 {
+		t.Errorf("expected 48 vertices, got %d with %d normals", len(m.Verts), len(m.Normals))
+		return
+	}
+	for i, n := 0, m.TriCount(); i < n; i++ {
+		i1, i2, i3, ok := m.TriVerts(i)
+		if !ok {
+			t.Errorf("triangle %d doesn't point into Verts", i)
+			return
+		}
+		for _, v := range []int{i1, i2, i3} {
+			if !AlmostEqual3(&m.Normals[v], &m.FaceNormals[i]) {
+				t.Errorf("normal of vertex %d in triangle %d is wrong - expected %s got %s",
+					v, i, m.FaceNormals[i].String(), m.Normals[v].String())
+			}
+		}
+	}
+}
+
+func TestComputeNormalsFlatCrease(t *testing.T) {
+	m := &Mesh{
+		Verts: []Vec3{NewVec3(0, 0, 0), NewVec3(1, 0, 0), NewVec3(1, 1, 0), NewVec3(0, 1, 0.1)},
+		UVs:   []Vec2{{0, 0}, {1, 0}, {1, 1}, {0, 1}},
+	}
+	m.Tris = []Triangle{
+		{&m.Verts[0], &m.Verts[1], &m.Verts[2]},
+		{&m.Verts[0], &m.Verts[2], &m.Verts[3]},
+	}
+	if err := m.ComputeNormals(&NormalOptions{CreaseAngle: 0.5}); err != nil {
+		t.Errorf("unexpected error %s", err.Error())
+		return
+	}
+	if len(m.Verts) != 4 || len(m.UVs) != 4 {
+		t.Errorf("a slight bend shouldn't be split, got %d vertices", len(m.Verts))
+	}
+	if !AlmostEqual3(&m.Normals[0], &m.Normals[2]) {
+		t.Errorf("shared vertices should have the same normal, got %s and %s",
+			m.Normals[0].String(), m.Normals[2].String())
+	}
+
+	// a sharp fold splits the shared vertices, attributes are copied
+	m.Verts[3].Z = 2
+	if err := m.ComputeNormals(&NormalOptions{CreaseAngle: 0.5}); err != nil {
+		t.Errorf("unexpected error %s", err.Error())
+		return
+	}
+	if len(m.Verts) != 6 || len(m.UVs) != 6 || len(m.Normals) != 6 {
+		t.Errorf("expected 6 vertices, got %d with %d uvs", len(m.Verts), len(m.UVs))
+		return
+	}
+	testVec2(t, "copied UV", &m.UVs[2], &m.UVs[5])
+}
+
+func TestComputeNormalsInvalid(t *testing.T) {
+	m := &Mesh{Verts: make([]Vec3, 3)}
+	other := make([]Vec3, 3)
+	m.Tris = []Triangle{{&m.Verts[0], &m.Verts[1], &other[2]}}
+	if err := m.ComputeNormals(nil); err == nil {
+		t.Errorf("triangles outside of Verts should fail")
+	}
+}
@@ -0,0 +1,462 @@
+package vec32
+
+import (
+	"math"
+)
+
+// options for Mesh.SubdivideLoop() and Mesh.SubdivideCatmullClark()
+//
+// Edges between faces with normals more than CreaseAngle (radians) apart
+// stay sharp like boundaries, 0 disables it. Vertices with more than two
+// sharp edges are corners and keep their position.
+type SubdivideOptions struct {
+	Levels      int
+	CreaseAngle float32
+}
+
+func NewSubdivideDefaultOptions() *SubdivideOptions {
+	return &SubdivideOptions{
+		Levels:      1,
+		CreaseAngle: 0,
+	}
+}
+
+// one level of a subdivision, faces are polygons
+type subdivider struct {
+	verts  []Vec3
+	uvs    []Vec2
+	colors []Color
+	// the corners of face f are faceStart[f]:faceStart[f+1]
+	faceStart []int
+	corners   []int
+	// the edge from each corner to the next one of its face
+	cornerEdges []int
+	edges       [][2]int
+	// the first two faces and the number of faces of each edge
+	edgeFaces [][2]int
+	edgeCount []int
+	sharp     []bool
+}
+
+// Refine a triangle mesh by Loop subdivision
+//
+// Each level splits every triangle into four. Boundaries and sharp edges
+// follow a cubic B-spline curve. Texture coordinates and colors are
+// interpolated linearly, normals and FaceNormals are recomputed if
+// present, Groups are scaled to the new triangles. Split vertices make
+// holes, see Weld().
+func (m *Mesh) SubdivideLoop(opt *SubdivideOptions) error {
+	if opt == nil {
+		opt = NewSubdivideDefaultOptions()
+	}
+	idx, err := m.triIndexList()
+	if err != nil {
+		return err
+	}
+	if opt.Levels <= 0 {
+		return nil
+	}
+	s := m.newSubdivider(idx, 3)
+	s.findCreases(opt.CreaseAngle)
+	for level := 0; level < opt.Levels; level++ {
+		s = s.loop()
+	}
+	m.setSubdivided(s, false, 1<<(2*uint(opt.Levels)))
+	return nil
+}
+
+// Refine a mesh by Catmull-Clark subdivision
+//
+// The faces are the Quads if the mesh has them, else the triangles. Each
+// level splits a face with n corners into n quads, the result keeps them
+// in Quads. Creases, attributes and groups are handled like in
+// SubdivideLoop().
+func (m *Mesh) SubdivideCatmullClark(opt *SubdivideOptions) error {
+	if opt == nil {
+		opt = NewSubdivideDefaultOptions()
+	}
+	idx, err := m.triIndexList()
+	if err != nil {
+		return err
+	}
+	if opt.Levels <= 0 {
+		return nil
+	}
+	n := 3
+	// new triangles per former triangle
+	scale := 6
+	if len(m.Quads) > 0 && len(m.Quads) == 2*len(idx)/3 {
+		idx = make([]int, len(m.Quads))
+		for i, v := range m.Quads {
+			if int(v) >= len(m.Verts) {
+				return newErrorMesh("quad index out of range")
+			}
+			idx[i] = int(v)
+		}
+		n, scale = 4, 4
+	}
+	s := m.newSubdivider(idx, n)
+	s.findCreases(opt.CreaseAngle)
+	for level := 0; level < opt.Levels; level++ {
+		s = s.catmullClark()
+	}
+	m.setSubdivided(s, true, scale<<(2*uint(opt.Levels-1)))
+	return nil
+}
+
+// faces with n corners each
+func (m *Mesh) newSubdivider(idx []int, n int) *subdivider {
+	s := &subdivider{verts: m.Verts, corners: idx}
+	if len(m.UVs) == len(m.Verts) {
+		s.uvs = m.UVs
+	}
+	if len(m.Colors) == len(m.Verts) {
+		s.colors = m.Colors
+	}
+	s.faceStart = make([]int, len(idx)/n+1)
+	for f := range s.faceStart {
+		s.faceStart[f] = n * f
+	}
+	s.buildEdges(nil)
+	return s
+}
+
+func (s *subdivider) numFaces() int {
+	return len(s.faceStart) - 1
+}
+
+// find the edges of the faces, the listed ones are sharp
+func (s *subdivider) buildEdges(sharp [][2]int) {
+	edgeMap := make(map[[2]int]int, len(s.corners))
+	s.cornerEdges = make([]int, len(s.corners))
+	for f := 0; f < s.numFaces(); f++ {
+		start, end := s.faceStart[f], s.faceStart[f+1]
+		for c := start; c < end; c++ {
+			next := c + 1
+			if next == end {
+				next = start
+			}
+			key := edgeKey(s.corners[c], s.corners[next])
+			e, ok := edgeMap[key]
+			if !ok {
+				e = len(s.edges)
+				edgeMap[key] = e
+				s.edges = append(s.edges, key)
+				s.edgeFaces = append(s.edgeFaces, [2]int{f, -1})
+				s.edgeCount = append(s.edgeCount, 0)
+			} else if s.edgeCount[e] == 1 {
+				s.edgeFaces[e][1] = f
+			}
+			s.edgeCount[e] += 1
+			s.cornerEdges[c] = e
+		}
+	}
+	s.sharp = make([]bool, len(s.edges))
+	for e, cnt := range s.edgeCount {
+		// boundaries and non-manifold edges
+		s.sharp[e] = cnt != 2
+	}
+	for _, key := range sharp {
+		if e, ok := edgeMap[key]; ok {
+			s.sharp[e] = true
+		}
+	}
+}
+
+func edgeKey(a, b int) [2]int {
+	if b < a {
+		a, b = b, a
+	}
+	return [2]int{a, b}
+}
+
+// mark the edges between faces meeting at more than the crease angle
+func (s *subdivider) findCreases(creaseAngle float32) {
+	if creaseAngle <= 0 {
+		return
+	}
+	normals := make([]Vec3, s.numFaces())
+	for f := range normals {
+		// Newell's method, for faces which aren't planar
+		var n Vec3
+		start, end := s.faceStart[f], s.faceStart[f+1]
+		for c := start; c < end; c++ {
+			next := c + 1
+			if next == end {
+				next = start
+			}
+			n = n.Plus(s.verts[s.corners[c]].Crossed(s.verts[s.corners[next]]))
+		}
+		normalizeNonZero(&n)
+		normals[f] = n
+	}
+	cosCrease := Cos(creaseAngle)
+	for e, faces := range s.edgeFaces {
+		if s.edgeCount[e] == 2 && normals[faces[0]].Inner(normals[faces[1]]) < cosCrease {
+			s.sharp[e] = true
+		}
+	}
+}
+
+// sums over the edges of each vertex
+type subdivVert struct {
+	nEdges, nSharp    int
+	neighbors, sharps Vec3
+}
+
+func (s *subdivider) vertSums() []subdivVert {
+	sums := make([]subdivVert, len(s.verts))
+	for e, edge := range s.edges {
+		for k, v := range edge {
+			w := &s.verts[edge[1-k]]
+			sums[v].nEdges += 1
+			sums[v].neighbors = sums[v].neighbors.Plus(*w)
+			if s.sharp[e] {
+				sums[v].nSharp += 1
+				sums[v].sharps = sums[v].sharps.Plus(*w)
+			}
+		}
+	}
+	return sums
+}
+
+// the position of an old vertex by the crease and corner rules, false if
+// the smooth rule applies
+func (s *subdivider) sharpVert(v int, sum *subdivVert, p *Vec3) bool {
+	switch {
+	case sum.nSharp == 2:
+		*p = s.verts[v].Scaled(0.75).Plus(sum.sharps.Scaled(0.125))
+	case sum.nSharp > 2:
+		*p = s.verts[v]
+	default:
+		return false
+	}
+	return true
+}
+
+// the next level with the old vertices first, then one per edge and
+// (with faces) one per face
+func (s *subdivider) next(faces int) *subdivider {
+	n := len(s.verts) + len(s.edges) + faces
+	next := &subdivider{verts: make([]Vec3, n)}
+	if s.uvs != nil {
+		next.uvs = make([]Vec2, n)
+		copy(next.uvs, s.uvs)
+	}
+	if s.colors != nil {
+		next.colors = make([]Color, n)
+		copy(next.colors, s.colors)
+	}
+	for e, edge := range s.edges {
+		next.interpolate(len(s.verts)+e, s, edge[:])
+	}
+	return next
+}
+
+// set the attributes of vertex v to the average of the ones of verts in
+// the former level
+func (s *subdivider) interpolate(v int, prev *subdivider, verts []int) {
+	w := 1 / float32(len(verts))
+	if s.uvs != nil {
+		var uv Vec2
+		for _, i := range verts {
+			uv.X += prev.uvs[i].X * w
+			uv.Y += prev.uvs[i].Y * w
+		}
+		s.uvs[v] = uv
+	}
+	if s.colors != nil {
+		var c Color
+		for _, i := range verts {
+			pc := &prev.colors[i]
+			c.R += pc.R * w
+			c.G += pc.G * w
+			c.B += pc.B * w
+			c.A += pc.A * w
+		}
+		s.colors[v] = c
+	}
+}
+
+// the halves of the sharp edges
+func (s *subdivider) sharpHalves() [][2]int {
+	var sharp [][2]int
+	for e, edge := range s.edges {
+		if s.sharp[e] {
+			mid := len(s.verts) + e
+			sharp = append(sharp, edgeKey(edge[0], mid), edgeKey(mid, edge[1]))
+		}
+	}
+	return sharp
+}
+
+// one level of Loop subdivision, the faces are triangles
+func (s *subdivider) loop() *subdivider {
+	nVerts := len(s.verts)
+	next := s.next(0)
+	sums := s.vertSums()
+	for v := range s.verts {
+		sum := &sums[v]
+		p := &next.verts[v]
+		if s.sharpVert(v, sum, p) {
+			continue
+		}
+		if sum.nEdges == 0 {
+			*p = s.verts[v]
+			continue
+		}
+		// Loop's original weights
+		n := float32(sum.nEdges)
+		c := 0.375 + 0.25*Cos(2*math.Pi/n)
+		beta := (0.625 - c*c) / n
+		*p = s.verts[v].Scaled(1 - n*beta).Plus(sum.neighbors.Scaled(beta))
+	}
+	for e, edge := range s.edges {
+		a, b := s.verts[edge[0]], s.verts[edge[1]]
+		p := &next.verts[nVerts+e]
+		if s.sharp[e] {
+			*p = a.Plus(b).Scaled(0.5)
+			continue
+		}
+		opposite := Vec3{}
+		for _, f := range s.edgeFaces[e] {
+			for c := s.faceStart[f]; c < s.faceStart[f+1]; c++ {
+				if v := s.corners[c]; v != edge[0] && v != edge[1] {
+					opposite = opposite.Plus(s.verts[v])
+				}
+			}
+		}
+		*p = a.Plus(b).Scaled(0.375).Plus(opposite.Scaled(0.125))
+	}
+	next.corners = make([]int, 0, 4*len(s.corners))
+	for f := 0; f < s.numFaces(); f++ {
+		c := 3 * f
+		a, b, d := s.corners[c], s.corners[c+1], s.corners[c+2]
+		ab := nVerts + s.cornerEdges[c]
+		bd := nVerts + s.cornerEdges[c+1]
+		da := nVerts + s.cornerEdges[c+2]
+		next.corners = append(next.corners, a, ab, da, ab, b, bd, da, bd, d, ab, bd, da)
+	}
+	next.faceStart = make([]int, 4*s.numFaces()+1)
+	for f := range next.faceStart {
+		next.faceStart[f] = 3 * f
+	}
+	next.buildEdges(s.sharpHalves())
+	return next
+}
+
+// one level of Catmull-Clark subdivision, the result are quads
+func (s *subdivider) catmullClark() *subdivider {
+	nVerts, nEdges := len(s.verts), len(s.edges)
+	next := s.next(s.numFaces())
+	// face points
+	facePoints := next.verts[nVerts+nEdges:]
+	for f := range facePoints {
+		start, end := s.faceStart[f], s.faceStart[f+1]
+		var p Vec3
+		for c := start; c < end; c++ {
+			p = p.Plus(s.verts[s.corners[c]])
+		}
+		facePoints[f] = p.Scaled(1 / float32(end-start))
+		next.interpolate(nVerts+nEdges+f, s, s.corners[start:end])
+	}
+	// edge points
+	for e, edge := range s.edges {
+		mid := s.verts[edge[0]].Plus(s.verts[edge[1]])
+		p := &next.verts[nVerts+e]
+		if s.sharp[e] {
+			*p = mid.Scaled(0.5)
+			continue
+		}
+		faces := s.edgeFaces[e]
+		*p = mid.Plus(facePoints[faces[0]]).Plus(facePoints[faces[1]]).Scaled(0.25)
+	}
+	// old vertices, with the average of the face points around them
+	faceSums := make([]Vec3, nVerts)
+	faceCounts := make([]int, nVerts)
+	for f := 0; f < s.numFaces(); f++ {
+		for c := s.faceStart[f]; c < s.faceStart[f+1]; c++ {
+			v := s.corners[c]
+			faceSums[v] = faceSums[v].Plus(facePoints[f])
+			faceCounts[v] += 1
+		}
+	}
+	sums := s.vertSums()
+	for v := range s.verts {
+		sum := &sums[v]
+		p := &next.verts[v]
+		if s.sharpVert(v, sum, p) {
+			continue
+		}
+		if sum.nEdges == 0 {
+			*p = s.verts[v]
+			continue
+		}
+		n := float32(sum.nEdges)
+		pv := s.verts[v]
+		favg := faceSums[v].Scaled(1 / float32(faceCounts[v]))
+		// twice the average of the edge midpoints
+		r2 := pv.Plus(sum.neighbors.Scaled(1 / n))
+		*p = favg.Plus(r2).Plus(pv.Scaled(n - 3)).Scaled(1 / n)
+	}
+	next.corners = make([]int, 0, 4*len(s.corners))
+	for f := 0; f < s.numFaces(); f++ {
+		start, end := s.faceStart[f], s.faceStart[f+1]
+		for c := start; c < end; c++ {
+			prev := c - 1
+			if c == start {
+				prev = end - 1
+			}
+			next.corners = append(next.corners, s.corners[c], nVerts+s.cornerEdges[c],
+				nVerts+nEdges+f, nVerts+s.cornerEdges[prev])
+		}
+	}
+	next.faceStart = make([]int, len(next.corners)/4+1)
+	for f := range next.faceStart {
+		next.faceStart[f] = 4 * f
+	}
+	next.buildEdges(s.sharpHalves())
+	return next
+}
+
+// write the last level to the mesh, each former triangle became scale
+// triangles
+func (m *Mesh) setSubdivided(s *subdivider, quads bool, scale int) {
+	hadFaceNormals := len(m.FaceNormals) > 0
+	hadNormals := len(m.Normals) == len(m.Verts)
+	m.Verts = s.verts
+	if s.uvs != nil {
+		m.UVs = s.uvs
+	}
+	if s.colors != nil {
+		m.Colors = s.colors
+	}
+	idx := s.corners
+	if quads {
+		idx = make([]int, 0, 6*s.numFaces())
+		for c := 0; c < len(s.corners); c += 4 {
+			q := s.corners[c : c+4]
+			idx = append(idx, q[0], q[1], q[2], q[0], q[2], q[3])
+		}
+	}
+	m.setTriIndices(idx)
+	if quads {
+		m.Quads = make([]uint32, len(s.corners))
+		for i, v := range s.corners {
+			m.Quads[i] = uint32(v)
+		}
+	}
+	for i := range m.Groups {
+		m.Groups[i].Start *= scale
+		m.Groups[i].End *= scale
+	}
+	m.FaceNormals = nil
+	if hadNormals {
+		m.ComputeNormals(&NormalOptions{Weighting: NORMAL_WEIGHT_AREA})
+		if !hadFaceNormals {
+			m.FaceNormals = nil
+		}
+	} else if hadFaceNormals {
+		m.ComputeFaceNormals()
+	}
+}
@@ -0,0 +1,160 @@
+package vec32
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestReadPLYQuads(t *testing.T) {
+	m, err := getMesh(t, 0, "two_cubes.ply")
+	if err != nil {
+		return
+	}
+	if len(m.Quads) != 4*12 || m.Quads[4] != 7 || m.Quads[7] != 4 {
+		t.Errorf("expected the 12 quads of the file, got %v", m.Quads)
+	}
+	for i := 0; i < len(m.Quads)/4; i++ {
+		q := m.Quads[4*i : 4*i+4]
+		i1, _, i3, _ := m.TriVerts(2 * i)
+		_, i5, i6, _ := m.TriVerts(2*i + 1)
+		if uint32(i1) != q[0] || uint32(i3) != q[2] || uint32(i5) != q[2] || uint32(i6) != q[3] {
+			t.Errorf("quad %d doesn't match its triangles", i)
+		}
+	}
+	if _, err := m.Weld(nil); err != nil || m.Quads != nil {
+		t.Errorf("changing the triangles should drop the quads")
+	}
+	mixed := strings.Replace(weldPLY, "4 4 5 6 7", "3 4 5 6", 1)
+	if m, err := ReadPLY(newReader(mixed)); err != nil || m.Quads != nil {
+		t.Errorf("quads are only kept if all faces are quads")
+	}
+}
+
+func TestSubdivideCatmullClark(t *testing.T) {
+	m, err := getMesh(t, 0, "two_cubes.ply")
+	if err != nil {
+		return
+	}
+	m.Groups = []MeshGroup{{Name: "second", Start: 12, End: 24}}
+	if err := m.SubdivideCatmullClark(nil); err != nil {
+		t.Fatalf("unexpected error %s", err.Error())
+	}
+	// each cube has 8 corners, 12 edge and 6 face points
+	if len(m.Verts) != 2*26 || len(m.Quads) != 4*48 || m.TriCount() != 96 {
+		t.Errorf("expected 52 vertices and 48 quads, got %d and %d", len(m.Verts), len(m.Quads)/4)
+	}
+	testVec3Near(t, "corner", NewVec3(2.0/9, 2.0/9, 2.0/9), m.Verts[0])
+	if g := m.Groups[0]; g.Start != 48 || g.End != 96 {
+		t.Errorf("the group should be scaled, got %d-%d", g.Start, g.End)
+	}
+	if r := m.Validate(); !r.IsValid() {
+		t.Errorf("the result should be valid, got %v", r.Problems())
+	}
+	if _, err := NewBVHTree(m, nil); err != nil {
+		t.Errorf("unexpected error %s", err.Error())
+	}
+}
+
+func TestSubdivideCreases(t *testing.T) {
+	m, err := getMesh(t, 0, "two_cubes.ply")
+	if err != nil {
+		return
+	}
+	opt := NewSubdivideDefaultOptions()
+	opt.Levels = 2
+	opt.CreaseAngle = 0.5
+	if err := m.SubdivideCatmullClark(opt); err != nil {
+		t.Fatalf("unexpected error %s", err.Error())
+	}
+	// all edges of the cubes are sharp, the shape doesn't change
+	for _, v := range m.Verts[:16] {
+		if v.X != 0 && v.X != 1 && v.X != 3 && v.X != 4 {
+			t.Errorf("the corner %s has moved", v.String())
+		}
+	}
+	for _, v := range m.Verts {
+		x := v.X
+		if x >= 3 {
+			x -= 3
+		}
+		if x != 0 && x != 1 && v.Y != 0 && v.Y != 1 && v.Z != 0 && v.Z != 1 {
+			t.Errorf("%s isn't on a cube", v.String())
+		}
+	}
+}
+
+func TestSubdivideTriangles(t *testing.T) {
+	// Catmull-Clark splits a triangle into three quads
+	m := newIndexedMesh(4, 0, 2, 1, 0, 1, 3, 1, 2, 3, 0, 3, 2)
+	m.Groups = []MeshGroup{{Start: 1, End: 2}}
+	if err := m.SubdivideCatmullClark(nil); err != nil {
+		t.Fatalf("unexpected error %s", err.Error())
+	}
+	if len(m.Verts) != 4+6+4 || len(m.Quads) != 4*12 || m.TriCount() != 24 {
+		t.Errorf("expected 14 vertices and 12 quads, got %d and %d", len(m.Verts), len(m.Quads)/4)
+	}
+	if g := m.Groups[0]; g.Start != 6 || g.End != 12 {
+		t.Errorf("the group should be scaled, got %d-%d", g.Start, g.End)
+	}
+	if r := m.Validate(); !r.IsValid() {
+		t.Errorf("the result should be valid, got %v", r.Problems())
+	}
+}
+
+func TestSubdivideLoop(t *testing.T) {
+	m := newIndexedMesh(4, 0, 2, 1, 0, 1, 3, 1, 2, 3, 0, 3, 2)
+	if err := m.ComputeNormals(nil); err != nil {
+		t.Fatalf("unexpected error %s", err.Error())
+	}
+	m.FaceNormals = nil
+	opt := NewSubdivideDefaultOptions()
+	opt.Levels = 2
+	if err := m.SubdivideLoop(opt); err != nil {
+		t.Fatalf("unexpected error %s", err.Error())
+	}
+	// 10 vertices and 24 edges after the first level
+	if len(m.Verts) != 10+24 || m.TriCount() != 64 || len(m.Normals) != len(m.Verts) {
+		t.Errorf("expected 34 vertices and 64 triangles, got %d and %d", len(m.Verts), m.TriCount())
+	}
+	if len(m.FaceNormals) != 0 || m.Quads != nil {
+		t.Errorf("FaceNormals and Quads shouldn't be added")
+	}
+	if r := m.Validate(); !r.IsValid() {
+		t.Errorf("the result should be valid, got %v", r.Problems())
+	}
+}
+
+func TestSubdivideLoopBoundary(t *testing.T) {
+	m := newGridMesh(2)
+	m.ComputeFaceNormals()
+	if err := m.SubdivideLoop(nil); err != nil {
+		t.Fatalf("unexpected error %s", err.Error())
+	}
+	if len(m.Verts) != 25 || m.TriCount() != 32 || len(m.FaceNormals) != 32 {
+		t.Errorf("expected 25 vertices and 32 triangles, got %d and %d", len(m.Verts), m.TriCount())
+	}
+	for _, n := range m.FaceNormals {
+		testVec3(t, "face normal", NewVec3(0, 0, 1), n)
+	}
+	// the boundary is smoothed as a curve
+	testVec3(t, "corner", NewVec3(0.125, 0.125, 0), m.Verts[0])
+	testVec3(t, "boundary", NewVec3(1, 0, 0), m.Verts[1])
+	if uv := m.UVs[len(m.UVs)-1]; uv.X+uv.Y <= 0 {
+		t.Errorf("texture coordinates should be interpolated")
+	}
+	if r := m.Validate(); len(r.Problems()) != 1 || len(r.Holes) != 1 {
+		t.Errorf("expected the outline as the only problem, got %v", r.Problems())
+	}
+}
+
+func TestSubdivideInvalid(t *testing.T) {
+	m := &Mesh{Verts: make([]Vec3, 2), Indices: []uint32{0, 1, 2}}
+	if err := m.SubdivideLoop(nil); err == nil {
+		t.Errorf("out of range indices should fail")
+	}
+	m = newIndexedMesh(4, 0, 1, 2, 0, 2, 3)
+	m.Quads = []uint32{0, 1, 2, 4}
+	if err := m.SubdivideCatmullClark(nil); err == nil {
+		t.Errorf("out of range quads should fail")
+	}
+}
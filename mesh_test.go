@@ -0,0 +1,138 @@
+package vec32
+
+import (
+	"testing"
+)
+
+func TestVertIndex(t *testing.T) {
+	m := &Mesh{Verts: make([]Vec3, 4)}
+	other := make([]Vec3, 4)
+	var cases = []struct {
+		p   *Vec3
+		idx int
+	}{
+		{&m.Verts[0], 0},
+		{&m.Verts[3], 3},
+		{&other[0], -1},
+		{nil, -1},
+	}
+	for i, tc := range cases {
+		if idx := m.VertIndex(tc.p); idx != tc.idx {
+			t.Errorf("tc %d: expected index %d, got %d", i, tc.idx, idx)
+		}
+	}
+	empty := &Mesh{}
+	if idx := empty.VertIndex(&other[0]); idx != -1 {
+		t.Errorf("expected index -1 on empty mesh, got %d", idx)
+	}
+}
+
+func TestInterpolate(t *testing.T) {
+	m := &Mesh{
+		Verts:   []Vec3{NewVec3(0, 0, 0), NewVec3(1, 0, 0), NewVec3(0, 1, 0)},
+		Normals: []Vec3{NewVec3(0, 0, 1), NewVec3(1, 0, 0), NewVec3(0, 1, 0)},
+		UVs:     []Vec2{{0, 0}, {1, 0}, {0, 1}},
+		Colors:  []Color{{1, 0, 0, 1}, {0, 1, 0, 1}, {0, 0, 1, 0}},
+	}
+	m.Tris = []Triangle{{&m.Verts[0], &m.Verts[1], &m.Verts[2]}}
+	r := Ray{NewVec3(0.5, 0.25, -1), NewVec3(0, 0, 1)}
+	var hit Intersection
+	if r.Intersect(&m.Tris[0], &hit) != 1 {
+		t.Errorf("ray should hit the triangle")
+		return
+	}
+	var n Vec3
+	var uv Vec2
+	var c Color
+	if !m.InterpolateNormal(0, &hit, &n) || !m.InterpolateUV(0, &hit, &uv) ||
+		!m.InterpolateColor(0, &hit, &c) {
+		t.Errorf("missing attributes")
+		return
+	}
+	testVec3(t, "InterpolateNormal()", unitVec3(0.5, 0.25, 0.25), n)
+	testVec2(t, "InterpolateUV()", &Vec2{0.5, 0.25}, &uv)
+	if !colorAlmostEqual(&c, &Color{0.25, 0.5, 0.25, 0.75}) {
+		t.Errorf("InterpolateColor() is wrong - got %v", c)
+	}
+
+	m.Normals, m.UVs, m.Colors = nil, nil, nil
+	if m.InterpolateNormal(0, &hit, &n) || m.InterpolateUV(0, &hit, &uv) ||
+		m.InterpolateColor(0, &hit, &c) {
+		t.Errorf("interpolation without attributes should fail")
+	}
+}
+
+func colorAlmostEqual(a, b *Color) bool {
+	return AlmostEqual(a.R, b.R) && AlmostEqual(a.G, b.G) &&
+		AlmostEqual(a.B, b.B) && AlmostEqual(a.A, b.A)
+}
+
+func TestMeshIndices(t *testing.T) {
+	m, err := getMesh(t, 0, "two_cubes.ply")
+	if err != nil {
+		return
+	}
+	// only the indices are filled in
+	if len(m.Indices) != 3*24 || m.TriCount() != 24 || m.Tris != nil {
+		t.Errorf("expected 24 triangles, got %d indices and %d tris", len(m.Indices), len(m.Tris))
+		return
+	}
+	for i := 0; i < m.TriCount(); i++ {
+		if tri := m.Tri(i); tri.P1 != &m.Verts[m.Indices[3*i]] || tri.P3 != &m.Verts[m.Indices[3*i+2]] {
+			t.Errorf("Tri(%d) differs from Indices: %s", i, tri.String())
+		}
+		i1, i2, i3, ok := m.TriVerts(i)
+		if !ok || uint32(i1) != m.Indices[3*i] || uint32(i2) != m.Indices[3*i+1] ||
+			uint32(i3) != m.Indices[3*i+2] {
+			t.Errorf("TriVerts(%d) is wrong - got %d %d %d", i, i1, i2, i3)
+		}
+	}
+
+	// appending reallocates Verts, the indices stay valid
+	n := uint32(len(m.Verts))
+	m.Verts = append(m.Verts, NewVec3(10, 0, 0), NewVec3(10, 1, 0), NewVec3(10, 0, 1))
+	m.Indices = append(m.Indices, n, n+1, n+2)
+	bvh, err := NewBVHTree(m, nil)
+	if err != nil {
+		t.Errorf("unexpected error %s", err.Error())
+		return
+	}
+	r := Ray{NewVec3(5, 0.25, 0.25), NewVec3(1, 0, 0)}
+	var hit Intersection
+	if idx, _ := bvh.Intersect(&r, &hit); idx != 24 {
+		t.Errorf("expected to hit the appended triangle, got %d", idx)
+	}
+	m.SyncTris()
+	if len(m.Tris) != 25 || m.Tris[24].P1 != &m.Verts[n] {
+		t.Errorf("SyncTris() didn't recreate the triangles")
+	}
+
+	m.Indices = append(m.Indices, 0, 1, n+3)
+	if _, err = NewBVHTree(m, nil); err == nil {
+		t.Errorf("out of range indices should fail")
+	}
+}
+
+func TestMeshSyncIndices(t *testing.T) {
+	m := &Mesh{Verts: make([]Vec3, 4)}
+	m.Tris = []Triangle{{&m.Verts[0], &m.Verts[1], &m.Verts[2]}, {&m.Verts[3], &m.Verts[2], &m.Verts[1]}}
+	if m.TriCount() != 2 {
+		t.Errorf("expected 2 triangles from Tris, got %d", m.TriCount())
+	}
+	if err := m.SyncIndices(); err != nil || m.Tris != nil {
+		t.Errorf("SyncIndices() should convert the triangles, got error %v", err)
+		return
+	}
+	exp := []uint32{0, 1, 2, 3, 2, 1}
+	for i := range exp {
+		if m.Indices[i] != exp[i] {
+			t.Errorf("SyncIndices() is wrong - expected %v got %v", exp, m.Indices)
+			break
+		}
+	}
+	other := NewVec3(0, 0, 0)
+	m.Tris = []Triangle{{&m.Verts[0], &m.Verts[1], &other}}
+	if err := m.SyncIndices(); err == nil {
+		t.Errorf("triangles outside of Verts should fail")
+	}
+}
@@ -0,0 +1,249 @@
+package vec32
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Problems found by Mesh.Validate()
+//
+// Triangles are given by their index, edges by their vertices.
+type MeshReport struct {
+	// triangles with vertex indices out of range, they aren't checked further
+	InvalidTris []int
+	// vertices with NaN or Inf coordinates
+	InvalidVerts []int
+	// vertices not used by any triangle
+	UnusedVerts []int
+	// triangles with (almost) zero area
+	DegeneratedTris []int
+	// edges shared by more than two triangles, the lower vertex first
+	NonManifoldEdges [][2]int
+	// vertices whose triangles form more than one fan
+	NonManifoldVerts []int
+	// loops of boundary edges
+	Holes [][]int
+	// edges whose two triangles run along it in the same direction
+	InconsistentEdges [][2]int
+	// pairs of triangles crossing each other
+	SelfIntersections [][2]int
+}
+
+// maximum number of items listed per problem by MeshReport.Problems()
+const REPORT_MAX_ITEMS = 5
+
+// Check a mesh for problems
+//
+// Triangles sharing a vertex aren't tested for intersections, neither are
+// coplanar triangles.
+func (m *Mesh) Validate() *MeshReport {
+	r := &MeshReport{}
+	n := len(m.Verts)
+	used := make([]bool, n)
+	for i := range m.Verts {
+		if !isFinite3(&m.Verts[i]) {
+			r.InvalidVerts = append(r.InvalidVerts, i)
+		}
+	}
+	// triangles with three distinct vertices, for the topology checks
+	valid := &Mesh{Verts: m.Verts}
+	// triangles with a proper shape, for the intersection checks
+	proper := &Mesh{Verts: m.Verts}
+	var properTris []int
+	for i, cnt := 0, m.TriCount(); i < cnt; i++ {
+		i1, i2, i3, ok := m.TriVerts(i)
+		if !ok {
+			r.InvalidTris = append(r.InvalidTris, i)
+			continue
+		}
+		used[i1], used[i2], used[i3] = true, true, true
+		if i1 == i2 || i2 == i3 || i1 == i3 {
+			r.DegeneratedTris = append(r.DegeneratedTris, i)
+			continue
+		}
+		valid.Indices = append(valid.Indices, uint32(i1), uint32(i2), uint32(i3))
+		tri := m.Tri(i)
+		if !isFinite3(tri.P1) || !isFinite3(tri.P2) || !isFinite3(tri.P3) {
+			continue
+		}
+		if isDegenerated(&tri) {
+			r.DegeneratedTris = append(r.DegeneratedTris, i)
+			continue
+		}
+		proper.Indices = append(proper.Indices, uint32(i1), uint32(i2), uint32(i3))
+		properTris = append(properTris, i)
+	}
+	for i := range used {
+		if !used[i] {
+			r.UnusedVerts = append(r.UnusedVerts, i)
+		}
+	}
+	r.checkTopology(valid)
+	r.checkIntersections(proper, properTris)
+	return r
+}
+
+func (r *MeshReport) checkTopology(m *Mesh) {
+	h, err := NewHalfEdgeMesh(m)
+	if err != nil {
+		return
+	}
+	for _, e := range h.NonManifoldEdges() {
+		a, b := h.From(e), h.To(e)
+		if b < a {
+			a, b = b, a
+		}
+		r.NonManifoldEdges = append(r.NonManifoldEdges, [2]int{a, b})
+	}
+	r.NonManifoldVerts = h.NonManifoldVerts()
+	r.Holes = h.BoundaryLoops()
+	for _, e := range h.InconsistentEdges() {
+		r.InconsistentEdges = append(r.InconsistentEdges, [2]int{h.From(e), h.To(e)})
+	}
+}
+
+// tris are the indices of the triangles of m in the validated mesh
+func (r *MeshReport) checkIntersections(m *Mesh, tris []int) {
+	bvh, err := NewBVHTree(m, nil)
+	if err != nil {
+		return
+	}
+	var candidates []int
+	for i := range tris {
+		a := m.Tri(i)
+		var bb OrthoBox
+		a.OrthoBox(&bb)
+		candidates = bvh.overlapping(&bb, candidates[:0])
+		for _, j := range candidates {
+			if j <= i || m.shareVert(i, j) {
+				continue
+			}
+			b := m.Tri(j)
+			if trianglesIntersect(&a, &b) {
+				r.SelfIntersections = append(r.SelfIntersections, [2]int{tris[i], tris[j]})
+			}
+		}
+	}
+}
+
+// Check if no problems were found
+func (r *MeshReport) IsValid() bool {
+	return len(r.Problems()) == 0
+}
+
+// Check if the mesh is closed and manifold
+func (r *MeshReport) IsWatertight() bool {
+	return len(r.InvalidTris) == 0 && len(r.Holes) == 0 &&
+		len(r.NonManifoldEdges) == 0 && len(r.NonManifoldVerts) == 0
+}
+
+// Get a description of each kind of problem found, listing the first items
+func (r *MeshReport) Problems() []string {
+	var p []string
+	add := func(what string, cnt int, item func(i int) string) {
+		if cnt == 0 {
+			return
+		}
+		items := make([]string, 0, REPORT_MAX_ITEMS+1)
+		for i := 0; i < cnt && i < REPORT_MAX_ITEMS; i++ {
+			items = append(items, item(i))
+		}
+		if cnt > REPORT_MAX_ITEMS {
+			items = append(items, "...")
+		}
+		p = append(p, fmt.Sprintf("%d %s (%s)", cnt, what, strings.Join(items, ", ")))
+	}
+	ints := func(v []int) func(int) string {
+		return func(i int) string { return fmt.Sprint(v[i]) }
+	}
+	pairs := func(v [][2]int, sep string) func(int) string {
+		return func(i int) string { return fmt.Sprintf("%d%s%d", v[i][0], sep, v[i][1]) }
+	}
+	add("triangles with invalid indices", len(r.InvalidTris), ints(r.InvalidTris))
+	add("vertices with NaN or Inf", len(r.InvalidVerts), ints(r.InvalidVerts))
+	add("unused vertices", len(r.UnusedVerts), ints(r.UnusedVerts))
+	add("degenerated triangles", len(r.DegeneratedTris), ints(r.DegeneratedTris))
+	add("non-manifold edges", len(r.NonManifoldEdges), pairs(r.NonManifoldEdges, "-"))
+	add("non-manifold vertices", len(r.NonManifoldVerts), ints(r.NonManifoldVerts))
+	add("holes", len(r.Holes), func(i int) string {
+		return fmt.Sprintf("%d edges at vertex %d", len(r.Holes[i]), r.Holes[i][0])
+	})
+	add("inconsistently oriented edges", len(r.InconsistentEdges), pairs(r.InconsistentEdges, "-"))
+	add("self-intersections", len(r.SelfIntersections), pairs(r.SelfIntersections, "/"))
+	return p
+}
+
+// Get an ErrorMesh listing the problems, nil if there are none
+func (r *MeshReport) Err() error {
+	p := r.Problems()
+	if len(p) == 0 {
+		return nil
+	}
+	return newErrorMesh("invalid mesh: " + strings.Join(p, "; "))
+}
+
+func (m *Mesh) shareVert(i, j int) bool {
+	a, b := m.Indices[3*i:3*i+3], m.Indices[3*j:3*j+3]
+	for _, va := range a {
+		for _, vb := range b {
+			if va == vb {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func isFinite3(v *Vec3) bool {
+	for _, c := range [3]float32{v.X, v.Y, v.Z} {
+		if IsNaN(c) || IsInf(c, 0) {
+			return false
+		}
+	}
+	return true
+}
+
+// zero area, relative to the longest edge
+func isDegenerated(tri *Triangle) bool {
+	e1, e2, e3 := tri.P2.Minus(*tri.P1), tri.P3.Minus(*tri.P1), tri.P3.Minus(*tri.P2)
+	longest := Max(e1.LengthSq(), Max(e2.LengthSq(), e3.LengthSq()))
+	c := e1.Crossed(e2)
+	return c.Length() <= EPS*longest
+}
+
+// check if an edge of one triangle passes through the other
+func trianglesIntersect(a, b *Triangle) bool {
+	for _, t := range [2][2]*Triangle{{a, b}, {b, a}} {
+		t1, t2 := t[0], t[1]
+		if segmentTriangle(t1.P1, t1.P2, t2) || segmentTriangle(t1.P2, t1.P3, t2) ||
+			segmentTriangle(t1.P3, t1.P1, t2) {
+			return true
+		}
+	}
+	return false
+}
+
+// segment-triangle-intersection by Möller–Trumbore, without culling
+func segmentTriangle(p, q *Vec3, tri *Triangle) bool {
+	d := q.Minus(*p)
+	e1, e2 := tri.P2.Minus(*tri.P1), tri.P3.Minus(*tri.P1)
+	pv := d.Crossed(e2)
+	det := e1.Inner(pv)
+	if Abs(det) <= EPS*e1.Length()*e2.Length()*d.Length() {
+		// parallel (or coplanar)
+		return false
+	}
+	inv := 1 / det
+	tv := p.Minus(*tri.P1)
+	u := tv.Inner(pv) * inv
+	if u < 0 || u > 1 {
+		return false
+	}
+	qv := tv.Crossed(e1)
+	v := d.Inner(qv) * inv
+	if v < 0 || u+v > 1 {
+		return false
+	}
+	t := e2.Inner(qv) * inv
+	return t >= 0 && t <= 1
+}
@@ -0,0 +1,117 @@
+package vec32
+
+import (
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestValidateClosed(t *testing.T) {
+	m, err := getMesh(t, 0, "two_cubes.ply")
+	if err != nil {
+		return
+	}
+	r := m.Validate()
+	if !r.IsValid() || !r.IsWatertight() || r.Err() != nil {
+		t.Errorf("the cubes should be valid, got %v", r.Problems())
+	}
+}
+
+func TestValidateProblems(t *testing.T) {
+	// a tetrahedron
+	tetra := []uint32{0, 2, 1, 0, 1, 3, 1, 2, 3, 0, 3, 2}
+	var cases = []struct {
+		name    string
+		mesh    *Mesh
+		check   func(r *MeshReport) interface{}
+		exp     interface{}
+		problem string
+	}{
+		{"invalid index", &Mesh{Verts: make([]Vec3, 4), Indices: append([]uint32{0, 1, 7}, tetra...)},
+			func(r *MeshReport) interface{} { return r.InvalidTris }, []int{0},
+			"1 triangles with invalid indices (0)"},
+		{"unused vertex", newIndexedMesh(5, tetra...),
+			func(r *MeshReport) interface{} { return r.UnusedVerts }, []int{4},
+			"1 unused vertices (4)"},
+		{"degenerated", newIndexedMesh(4, append(tetra, 0, 1, 1)...),
+			func(r *MeshReport) interface{} { return r.DegeneratedTris }, []int{4},
+			"1 degenerated triangles (4)"},
+		{"hole", newIndexedMesh(4, tetra[3:]...),
+			func(r *MeshReport) interface{} { return len(r.Holes) }, 1,
+			"1 holes (3 edges at vertex"},
+		{"non-manifold edge", newIndexedMesh(5, append(tetra, 0, 1, 4)...),
+			func(r *MeshReport) interface{} { return r.NonManifoldEdges }, [][2]int{{0, 1}},
+			"1 non-manifold edges (0-1)"},
+		{"inconsistent", newIndexedMesh(4, append([]uint32{0, 1, 2}, tetra[3:]...)...),
+			func(r *MeshReport) interface{} { return len(r.InconsistentEdges) }, 3,
+			"3 inconsistently oriented edges"},
+	}
+	for _, tc := range cases {
+		r := tc.mesh.Validate()
+		if cur := tc.check(r); !reflect.DeepEqual(cur, tc.exp) {
+			t.Errorf("%s: expected %v, got %v", tc.name, tc.exp, cur)
+		}
+		err := r.Err()
+		if r.IsValid() || err == nil || !strings.Contains(err.Error(), tc.problem) {
+			t.Errorf("%s: expected the problem '%s', got %v", tc.name, tc.problem, err)
+		}
+	}
+}
+
+func TestValidateVerts(t *testing.T) {
+	m := newIndexedMesh(4, 0, 2, 1, 0, 1, 3, 1, 2, 3, 0, 3, 2)
+	m.Verts[2].Y = NaN()
+	m.Verts[3].Z = Inf(-1)
+	r := m.Validate()
+	if !reflect.DeepEqual(r.InvalidVerts, []int{2, 3}) || len(r.DegeneratedTris) != 0 {
+		t.Errorf("expected vertices 2 and 3 to be invalid, got %v", r.InvalidVerts)
+	}
+	if !r.IsWatertight() {
+		t.Errorf("the topology of the mesh is fine")
+	}
+}
+
+func TestValidateSelfIntersections(t *testing.T) {
+	m := &Mesh{Verts: []Vec3{
+		NewVec3(0, 0, 0), NewVec3(2, 0, 0), NewVec3(0, 2, 0),
+		// crossing the first one
+		NewVec3(0.5, 0.5, -1), NewVec3(0.5, 0.5, 1), NewVec3(3, 3, 0),
+		// above both
+		NewVec3(0, 0, 5), NewVec3(1, 0, 5), NewVec3(0, 1, 5),
+		// sharing vertex 0 with the first one, the edge touches it
+		NewVec3(1, 1, 0), NewVec3(1, 1, 1),
+	}}
+	m.Indices = []uint32{0, 1, 2, 3, 4, 5, 6, 7, 8, 0, 9, 10}
+	r := m.Validate()
+	if !reflect.DeepEqual(r.SelfIntersections, [][2]int{{0, 1}}) {
+		t.Errorf("expected triangles 0 and 1 to intersect, got %v", r.SelfIntersections)
+	}
+}
+
+func TestValidateListLimit(t *testing.T) {
+	m := newIndexedMesh(10)
+	p := m.Validate().Problems()
+	if len(p) != 1 || p[0] != "10 unused vertices (0, 1, 2, 3, 4, ...)" {
+		t.Errorf("unexpected problems %v", p)
+	}
+}
+
+func TestReadPLYStrict(t *testing.T) {
+	if _, err := ReadPLYWithOptions(newReader(weldPLY), &PLYReadOptions{Strict: true}); err == nil ||
+		!strings.HasPrefix(err.Error(), "invalid mesh: ") {
+		t.Errorf("expected an invalid mesh, got %v", err)
+	}
+	if _, err := ReadPLYWithOptions(newReader(weldPLY), nil); err != nil {
+		t.Errorf("unexpected error %s", err.Error())
+	}
+	f, err := os.Open("test/ply/two_cubes.ply")
+	if err != nil {
+		t.Errorf("unexpected error %s", err.Error())
+		return
+	}
+	defer f.Close()
+	if _, err = ReadPLYWithOptions(f, &PLYReadOptions{Strict: true}); err != nil {
+		t.Errorf("unexpected error %s", err.Error())
+	}
+}
@@ -0,0 +1,256 @@
+package vec32
+
+import (
+	"math"
+)
+
+// options for welding the vertices of a mesh
+//
+// Vertices closer than Tolerance are merged, 0 merges only identical ones.
+// With Attributes, their normals, texture coordinates and colors have to
+// be within Tolerance as well, which keeps seams.
+type WeldOptions struct {
+	Tolerance  float32
+	Attributes bool
+}
+
+func NewWeldDefaultOptions() *WeldOptions {
+	return &WeldOptions{
+		Tolerance:  1e-5,
+		Attributes: false,
+	}
+}
+
+// What was removed by Mesh.Weld()
+type WeldResult struct {
+	// merged into other vertices
+	Verts int
+	// triangles with less than three distinct vertices after merging
+	Degenerated int
+	// triangles using the same vertices in the same order as an earlier one
+	Duplicates int
+}
+
+// grid cell of the spatial hash
+type weldCell [3]int64
+
+type weldBuilder struct {
+	m     *Mesh
+	opt   *WeldOptions
+	tolSq float32
+	// the new indices of the vertices in a cell
+	cells map[weldCell][]int
+	// the old indices of the kept vertices
+	merged []int
+}
+
+// Merge vertices closer than the tolerance
+//
+// The first vertex of each cluster is kept with its attributes. Triangles
+// are remapped, the ones degenerated by merging and duplicates are
+// removed. FaceNormals and Groups are kept consistent with the remaining
+// triangles.
+func (m *Mesh) Weld(opt *WeldOptions) (WeldResult, error) {
+	if opt == nil {
+		opt = NewWeldDefaultOptions()
+	}
+	var res WeldResult
+	idx, err := m.triIndexList()
+	if err != nil {
+		return res, err
+	}
+	wb := weldBuilder{
+		m:     m,
+		opt:   opt,
+		tolSq: opt.Tolerance * opt.Tolerance,
+		cells: make(map[weldCell][]int),
+	}
+	remap := wb.mergeVerts()
+	res.Verts = len(m.Verts) - len(wb.merged)
+	m.compactVerts(wb.merged)
+
+	keep := make([]bool, len(idx)/3)
+	seen := make(map[[3]int]bool, len(keep))
+	out := idx[:0]
+	for i := range keep {
+		a, b, c := remap[idx[3*i]], remap[idx[3*i+1]], remap[idx[3*i+2]]
+		if a == b || b == c || a == c {
+			res.Degenerated += 1
+			continue
+		}
+		// the same triangle may start at any of its vertices
+		key := [3]int{a, b, c}
+		if b < a && b < c {
+			key = [3]int{b, c, a}
+		} else if c < a && c < b {
+			key = [3]int{c, a, b}
+		}
+		if seen[key] {
+			res.Duplicates += 1
+			continue
+		}
+		seen[key] = true
+		keep[i] = true
+		out = append(out, a, b, c)
+	}
+	m.setTriIndices(out)
+	m.keepTris(keep)
+	return res, nil
+}
+
+// find the vertex each one is merged into, merged holds the kept ones
+func (wb *weldBuilder) mergeVerts() []int {
+	verts := wb.m.Verts
+	remap := make([]int, len(verts))
+	for i := range verts {
+		j, cell, ok := wb.find(i)
+		if !ok {
+			j = len(wb.merged)
+			wb.merged = append(wb.merged, i)
+			if cell != nil {
+				wb.cells[*cell] = append(wb.cells[*cell], j)
+			}
+		}
+		remap[i] = j
+	}
+	return remap
+}
+
+// search the cells around vertex i for one to merge with
+//
+// returns the new index of the vertex and the cell of i, nil if the vertex
+// can't be hashed (NaN or far too big for the tolerance)
+func (wb *weldBuilder) find(i int) (int, *weldCell, bool) {
+	v := &wb.m.Verts[i]
+	cell, ok := wb.cell(v)
+	if !ok {
+		return 0, nil, false
+	}
+	if wb.opt.Tolerance <= 0 {
+		for _, k := range wb.cells[cell] {
+			if wb.m.Verts[wb.merged[k]].IsEqual(v) && wb.sameAttributes(i, wb.merged[k]) {
+				return k, &cell, true
+			}
+		}
+		return 0, &cell, false
+	}
+	for dx := int64(-1); dx <= 1; dx++ {
+		for dy := int64(-1); dy <= 1; dy++ {
+			for dz := int64(-1); dz <= 1; dz++ {
+				n := weldCell{cell[0] + dx, cell[1] + dy, cell[2] + dz}
+				for _, k := range wb.cells[n] {
+					j := wb.merged[k]
+					if v.DistanceSq(&wb.m.Verts[j]) <= wb.tolSq && wb.sameAttributes(i, j) {
+						return k, &cell, true
+					}
+				}
+			}
+		}
+	}
+	return 0, &cell, false
+}
+
+func (wb *weldBuilder) cell(v *Vec3) (weldCell, bool) {
+	size := float64(wb.opt.Tolerance)
+	if size <= 0 {
+		size = 1
+	}
+	var cell weldCell
+	for i, c := range [3]float32{v.X, v.Y, v.Z} {
+		f := math.Floor(float64(c) / size)
+		if math.IsNaN(f) || math.Abs(f) > 1<<62 {
+			return cell, false
+		}
+		cell[i] = int64(f)
+	}
+	return cell, true
+}
+
+func (wb *weldBuilder) sameAttributes(i, j int) bool {
+	if !wb.opt.Attributes {
+		return true
+	}
+	m, tol := wb.m, wb.opt.Tolerance
+	if len(m.Normals) == len(m.Verts) && m.Normals[i].DistanceSq(&m.Normals[j]) > tol*tol {
+		return false
+	}
+	if len(m.UVs) == len(m.Verts) && m.UVs[i].DistanceSq(&m.UVs[j]) > tol*tol {
+		return false
+	}
+	if len(m.Colors) == len(m.Verts) {
+		ci, cj := &m.Colors[i], &m.Colors[j]
+		if Abs(ci.R-cj.R) > tol || Abs(ci.G-cj.G) > tol || Abs(ci.B-cj.B) > tol ||
+			Abs(ci.A-cj.A) > tol {
+			return false
+		}
+	}
+	return true
+}
+
+// keep only the vertices (and their attributes) of the increasing indices
+func (m *Mesh) compactVerts(keep []int) {
+	verts := make([]Vec3, len(keep))
+	for i, j := range keep {
+		verts[i] = m.Verts[j]
+	}
+	if len(m.Normals) == len(m.Verts) {
+		normals := make([]Vec3, len(keep))
+		for i, j := range keep {
+			normals[i] = m.Normals[j]
+		}
+		m.Normals = normals
+	}
+	if len(m.UVs) == len(m.Verts) {
+		uvs := make([]Vec2, len(keep))
+		for i, j := range keep {
+			uvs[i] = m.UVs[j]
+		}
+		m.UVs = uvs
+	}
+	if len(m.Colors) == len(m.Verts) {
+		colors := make([]Color, len(keep))
+		for i, j := range keep {
+			colors[i] = m.Colors[j]
+		}
+		m.Colors = colors
+	}
+	m.Verts = verts
+}
+
+// drop the face normals of removed triangles and shrink the groups
+//
+// keep tells for each of the former triangles whether it is still there,
+// Indices have to be updated already.
+func (m *Mesh) keepTris(keep []bool) {
+	// newIdx[i] is the new index of the former triangle i (or the next kept one)
+	newIdx := make([]int, len(keep)+1)
+	for i, k := range keep {
+		newIdx[i+1] = newIdx[i]
+		if k {
+			newIdx[i+1] += 1
+		}
+	}
+	if len(m.FaceNormals) == len(keep) {
+		normals := m.FaceNormals[:0]
+		for i, k := range keep {
+			if k {
+				normals = append(normals, m.FaceNormals[i])
+			}
+		}
+		m.FaceNormals = normals
+	}
+	groups := m.Groups[:0]
+	for _, g := range m.Groups {
+		if g.Start > len(keep) || g.End > len(keep) {
+			continue
+		}
+		g.Start, g.End = newIdx[g.Start], newIdx[g.End]
+		if g.End > g.Start {
+			groups = append(groups, g)
+		}
+	}
+	if len(groups) == 0 {
+		groups = nil
+	}
+	m.Groups = groups
+}
@@ -0,0 +1,135 @@
+package vec32
+
+import (
+	"testing"
+)
+
+// every face has its own vertices, like some exporters write them
+const weldPLY = "ply\n" +
+	"format ascii 1.0\n" +
+	"element vertex 8\n" +
+	"property float x\n" +
+	"property float y\n" +
+	"property float z\n" +
+	"property float u\n" +
+	"property float v\n" +
+	"element face 2\n" +
+	"property list uchar int vertex_index\n" +
+	"end_header\n" +
+	"0 0 0 0 0\n" +
+	"1 0 0 1 0\n" +
+	"1 1 0 1 1\n" +
+	"0 1 0 0 1\n" +
+	"1 0 0 1 0\n" +
+	"2 0 0 0 0\n" +
+	"2 1 0 0 1\n" +
+	"1 1.000001 0 1 1\n" +
+	"4 0 1 2 3\n" +
+	"4 4 5 6 7\n"
+
+func TestWeldPLY(t *testing.T) {
+	m, err := ReadPLY(newReader(weldPLY))
+	if err != nil {
+		t.Errorf("unexpected error %s", err.Error())
+		return
+	}
+	res, err := m.Weld(nil)
+	if err != nil {
+		t.Errorf("unexpected error %s", err.Error())
+		return
+	}
+	if res != (WeldResult{Verts: 2}) {
+		t.Errorf("expected 2 merged vertices, got %+v", res)
+	}
+	if len(m.Verts) != 6 || len(m.UVs) != 6 || m.TriCount() != 4 || m.Tris != nil {
+		t.Errorf("expected 6 verts and 4 tris, got %d verts, %d uvs and %d tris",
+			len(m.Verts), len(m.UVs), m.TriCount())
+		return
+	}
+	// the first vertex of a cluster is kept
+	testVec2(t, "UV of merged vertex", &Vec2{1, 1}, &m.UVs[2])
+	exp := []uint32{0, 1, 2, 0, 2, 3, 1, 4, 5, 1, 5, 2}
+	for i := range exp {
+		if m.Indices[i] != exp[i] {
+			t.Errorf("wrong indices after welding - expected %v got %v", exp, m.Indices)
+			break
+		}
+	}
+}
+
+func TestWeldTriangles(t *testing.T) {
+	m := &Mesh{Verts: []Vec3{
+		NewVec3(0, 0, 0), NewVec3(1, 0, 0), NewVec3(0, 1, 0), NewVec3(1, 0, 1e-6), NewVec3(0, 0, 1),
+	}}
+	m.Indices = []uint32{
+		0, 1, 2,
+		1, 2, 0, // duplicate
+		2, 1, 0, // back side
+		0, 1, 3, // degenerated after welding
+		0, 2, 4,
+		2, 4, 0, // duplicate
+	}
+	m.ComputeFaceNormals()
+	m.Groups = []MeshGroup{{Name: "a", Start: 0, End: 2}, {Name: "b", Start: 2, End: 4}, {Name: "c", Start: 4, End: 6}}
+	res, err := m.Weld(nil)
+	if err != nil {
+		t.Errorf("unexpected error %s", err.Error())
+		return
+	}
+	if res != (WeldResult{Verts: 1, Degenerated: 1, Duplicates: 2}) {
+		t.Errorf("unexpected result %+v", res)
+	}
+	if m.TriCount() != 3 || len(m.FaceNormals) != 3 {
+		t.Errorf("expected 3 triangles, got %d with %d face normals", m.TriCount(), len(m.FaceNormals))
+		return
+	}
+	testVec3(t, "face normal of the back side", NewVec3(0, 0, -1), m.FaceNormals[1])
+	expGroups := []MeshGroup{{Name: "a", Start: 0, End: 1}, {Name: "b", Start: 1, End: 2}, {Name: "c", Start: 2, End: 3}}
+	if len(m.Groups) != len(expGroups) {
+		t.Errorf("expected groups %v, got %v", expGroups, m.Groups)
+		return
+	}
+	for i := range expGroups {
+		if m.Groups[i] != expGroups[i] {
+			t.Errorf("expected groups %v, got %v", expGroups, m.Groups)
+			break
+		}
+	}
+}
+
+func TestWeldOptions(t *testing.T) {
+	newMesh := func() *Mesh {
+		m := &Mesh{
+			Verts: []Vec3{NewVec3(0, 0, 0), NewVec3(1, 0, 0), NewVec3(0, 1, 0), NewVec3(1, 0.001, 0)},
+			UVs:   []Vec2{{0, 0}, {1, 0}, {0, 1}, {0.5, 0}},
+		}
+		m.Indices = []uint32{0, 1, 2, 0, 2, 3}
+		return m
+	}
+	var cases = []struct {
+		opt   WeldOptions
+		verts int
+	}{
+		{WeldOptions{Tolerance: 0}, 4},
+		{WeldOptions{Tolerance: 0.01}, 3},
+		{WeldOptions{Tolerance: 0.01, Attributes: true}, 4},
+	}
+	for i, tc := range cases {
+		m := newMesh()
+		res, err := m.Weld(&tc.opt)
+		if err != nil {
+			t.Errorf("tc %d: unexpected error %s", i, err.Error())
+			continue
+		}
+		if len(m.Verts) != tc.verts || res.Verts != 4-tc.verts {
+			t.Errorf("tc %d: expected %d vertices, got %d (%+v)", i, tc.verts, len(m.Verts), res)
+		}
+	}
+
+	// vertices at the same position weld without tolerance
+	m := newMesh()
+	m.Verts[3] = m.Verts[1]
+	if res, _ := m.Weld(&WeldOptions{}); res.Verts != 1 || m.TriCount() != 2 {
+		t.Errorf("identical vertices should be welded, got %+v", res)
+	}
+}
@@ -0,0 +1,363 @@
+package vec32
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// a vertex of a face is a combination of position, texture coordinate and
+// normal. The first combination used for a position becomes the vertex of
+// the same index in the mesh, others are appended as extra vertices.
+type objVertex struct {
+	v, vt, vn int
+}
+
+type objBuilder struct {
+	mesh      *Mesh
+	lineNo    int
+	positions []Vec3
+	colors    []Color
+	uvs       []Vec2
+	normals   []Vec3
+	vertMap   map[objVertex]int
+	used      []objVertex
+	extra     []objVertex
+	indices   []int
+	group     MeshGroup
+	haveUVs   bool
+	haveNorms bool
+	haveCols  bool
+}
+
+// Read a mesh from a Wavefront OBJ file
+//
+// Polygons are added as triangle fans. Objects, groups and materials are
+// kept in Mesh.Groups, material libraries in Mesh.MaterialLibs.
+func ReadOBJ(r io.Reader) (*Mesh, error) {
+	ob := objBuilder{mesh: &Mesh{}, vertMap: make(map[objVertex]int)}
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	var line string
+	for scanner.Scan() {
+		ob.lineNo += 1
+		line += scanner.Text()
+		// a backslash continues the statement on the next line
+		if strings.HasSuffix(line, "\\") {
+			line = line[:len(line)-1] + " "
+			continue
+		}
+		if err := ob.readLine(line); err != nil {
+			return nil, err
+		}
+		line = ""
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, newErrorMesh(err.Error())
+	}
+	ob.finish()
+	return ob.mesh, nil
+}
+
+func (ob *objBuilder) errorf(format string, args ...interface{}) error {
+	return newErrorMesh(fmt.Sprintf("line %d: ", ob.lineNo) + fmt.Sprintf(format, args...))
+}
+
+func (ob *objBuilder) readLine(line string) error {
+	if i := strings.IndexByte(line, '#'); i >= 0 {
+		line = line[:i]
+	}
+	fields := strings.Fields(line)
+	if len(fields) == 0 {
+		return nil
+	}
+	args := fields[1:]
+	switch fields[0] {
+	case "v":
+		return ob.readPosition(args)
+	case "vt":
+		vals, err := ob.parseFloats(args, 1, 3)
+		if err != nil {
+			return err
+		}
+		ob.uvs = append(ob.uvs, Vec2{vals[0], vals[1]})
+	case "vn":
+		vals, err := ob.parseFloats(args, 3, 3)
+		if err != nil {
+			return err
+		}
+		ob.normals = append(ob.normals, NewVec3(vals[0], vals[1], vals[2]))
+	case "f":
+		return ob.readFace(args)
+	case "o":
+		ob.startGroup()
+		ob.group.Object = strings.Join(args, " ")
+		ob.group.Name = ""
+	case "g":
+		ob.startGroup()
+		ob.group.Name = strings.Join(args, " ")
+	case "usemtl":
+		ob.startGroup()
+		ob.group.Material = strings.Join(args, " ")
+	case "mtllib":
+		ob.mesh.MaterialLibs = append(ob.mesh.MaterialLibs, args...)
+	default:
+		// points, lines, smoothing groups, free-form geometry, ...
+		Trace.Printf("line %d: ignoring statement %s", ob.lineNo, fields[0])
+	}
+	return nil
+}
+
+// positions may be followed by a weight or a color
+func (ob *objBuilder) readPosition(args []string) error {
+	vals, err := ob.parseFloats(args, 3, 7)
+	if err != nil {
+		return err
+	}
+	ob.positions = append(ob.positions, NewVec3(vals[0], vals[1], vals[2]))
+	c := Color{1, 1, 1, 1}
+	if len(args) >= 6 {
+		ob.haveCols = true
+		c = Color{vals[3], vals[4], vals[5], 1}
+	}
+	ob.colors = append(ob.colors, c)
+	return nil
+}
+
+func (ob *objBuilder) parseFloats(args []string, min, max int) ([]float32, error) {
+	if len(args) < min || len(args) > max {
+		return nil, ob.errorf("expected %d to %d values, got %d", min, max, len(args))
+	}
+	// missing optional values are zero
+	vals := make([]float32, max)
+	for i, arg := range args {
+		v, err := strconv.ParseFloat(arg, 32)
+		if err != nil {
+			return nil, ob.errorf("could not convert `%s` to float", arg)
+		}
+		vals[i] = float32(v)
+	}
+	return vals, nil
+}
+
+func (ob *objBuilder) readFace(args []string) error {
+	if len(args) < 3 {
+		return ob.errorf("a face must have at least 3 indices")
+	}
+	var p0, p1, p2 int
+	var err error
+	if p0, err = ob.faceVertex(args[0]); err != nil {
+		return err
+	}
+	if p1, err = ob.faceVertex(args[1]); err != nil {
+		return err
+	}
+	for _, arg := range args[2:] {
+		if p2, err = ob.faceVertex(arg); err != nil {
+			return err
+		}
+		ob.indices = append(ob.indices, p0, p1, p2)
+		p1 = p2
+	}
+	return nil
+}
+
+// get the mesh vertex of v, v/vt, v//vn or v/vt/vn
+func (ob *objBuilder) faceVertex(arg string) (int, error) {
+	parts := strings.Split(arg, "/")
+	if len(parts) > 3 {
+		return 0, ob.errorf("invalid face vertex `%s`", arg)
+	}
+	var key objVertex
+	var err error
+	if key.v, err = ob.parseIndex(parts[0], len(ob.positions)); err != nil {
+		return 0, err
+	}
+	key.vt, key.vn = -1, -1
+	if len(parts) > 1 && parts[1] != "" {
+		if key.vt, err = ob.parseIndex(parts[1], len(ob.uvs)); err != nil {
+			return 0, err
+		}
+		ob.haveUVs = true
+	}
+	if len(parts) > 2 && parts[2] != "" {
+		if key.vn, err = ob.parseIndex(parts[2], len(ob.normals)); err != nil {
+			return 0, err
+		}
+		ob.haveNorms = true
+	}
+	idx, ok := ob.vertMap[key]
+	if !ok {
+		for len(ob.used) < len(ob.positions) {
+			ob.used = append(ob.used, objVertex{-1, -1, -1})
+		}
+		if ob.used[key.v].v < 0 {
+			ob.used[key.v] = key
+			idx = key.v
+		} else {
+			// extra vertices are numbered negative until all positions are known
+			ob.extra = append(ob.extra, key)
+			idx = -len(ob.extra)
+		}
+		ob.vertMap[key] = idx
+	}
+	return idx, nil
+}
+
+// indices start at 1, negative ones count back from the last element
+func (ob *objBuilder) parseIndex(s string, n int) (int, error) {
+	idx, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, ob.errorf("could not convert `%s` to int", s)
+	}
+	if idx < 0 {
+		idx += n
+	} else {
+		idx -= 1
+	}
+	if idx < 0 || idx >= n {
+		return 0, ob.errorf("vertex index out of range")
+	}
+	return idx, nil
+}
+
+// close the current group, if it has any triangles
+func (ob *objBuilder) startGroup() {
+	start := len(ob.indices) / 3
+	if start > ob.group.Start {
+		ob.group.End = start
+		ob.mesh.Groups = append(ob.mesh.Groups, ob.group)
+	}
+	ob.group.Start = start
+}
+
+func (ob *objBuilder) finish() {
+	// only store groups if the file has any
+	if len(ob.mesh.Groups) > 0 || ob.group != (MeshGroup{}) {
+		ob.startGroup()
+	}
+	m := ob.mesh
+	nPos := len(ob.positions)
+	n := nPos + len(ob.extra)
+	m.Verts = make([]Vec3, n)
+	if ob.haveNorms {
+		m.Normals = make([]Vec3, n)
+	}
+	if ob.haveUVs {
+		m.UVs = make([]Vec2, n)
+	}
+	if ob.haveCols {
+		m.Colors = make([]Color, n)
+	}
+	for i := 0; i < n; i++ {
+		key := objVertex{i, -1, -1}
+		if i >= nPos {
+			key = ob.extra[i-nPos]
+		} else if i < len(ob.used) && ob.used[i].v >= 0 {
+			key = ob.used[i]
+		}
+		m.Verts[i] = ob.positions[key.v]
+		if ob.haveNorms && key.vn >= 0 {
+			m.Normals[i] = ob.normals[key.vn]
+		}
+		if ob.haveUVs && key.vt >= 0 {
+			m.UVs[i] = ob.uvs[key.vt]
+		}
+		if ob.haveCols {
+			m.Colors[i] = ob.colors[key.v]
+		}
+	}
+	m.Indices = make([]uint32, len(ob.indices))
+	for i, idx := range ob.indices {
+		m.Indices[i] = uint32(ob.vertIndex(idx))
+	}
+}
+
+// resolve the numbering of extra vertices
+func (ob *objBuilder) vertIndex(idx int) int {
+	if idx < 0 {
+		return len(ob.positions) - idx - 1
+	}
+	return idx
+}
+
+// Write a mesh as Wavefront OBJ file
+//
+// Normals, texture coordinates and colors are written if the mesh has
+// them, groups as o, g and usemtl statements.
+func WriteOBJ(w io.Writer, m *Mesh) error {
+	if err := m.checkTris(); err != nil {
+		return err
+	}
+	wr := bufio.NewWriter(w)
+	for _, lib := range m.MaterialLibs {
+		fmt.Fprintf(wr, "mtllib %s\n", lib)
+	}
+	haveNorms := len(m.Normals) > 0 && len(m.Normals) == len(m.Verts)
+	haveUVs := len(m.UVs) > 0 && len(m.UVs) == len(m.Verts)
+	haveCols := len(m.Colors) > 0 && len(m.Colors) == len(m.Verts)
+	for i := range m.Verts {
+		v := &m.Verts[i]
+		wr.WriteString("v " + formatFloat(v.X) + " " + formatFloat(v.Y) + " " + formatFloat(v.Z))
+		if haveCols {
+			c := &m.Colors[i]
+			wr.WriteString(" " + formatFloat(c.R) + " " + formatFloat(c.G) + " " + formatFloat(c.B))
+		}
+		wr.WriteByte('\n')
+	}
+	if haveUVs {
+		for _, uv := range m.UVs {
+			wr.WriteString("vt " + formatFloat(uv.X) + " " + formatFloat(uv.Y) + "\n")
+		}
+	}
+	if haveNorms {
+		for _, n := range m.Normals {
+			wr.WriteString("vn " + formatFloat(n.X) + " " + formatFloat(n.Y) + " " + formatFloat(n.Z) + "\n")
+		}
+	}
+	var curr MeshGroup
+	groupIdx := 0
+	for i, n := 0, m.TriCount(); i < n; i++ {
+		for groupIdx < len(m.Groups) && m.Groups[groupIdx].Start == i {
+			writeOBJGroup(wr, &curr, &m.Groups[groupIdx])
+			groupIdx += 1
+		}
+		i1, i2, i3, _ := m.TriVerts(i)
+		wr.WriteString("f")
+		for _, idx := range [3]int{i1 + 1, i2 + 1, i3 + 1} {
+			s := strconv.Itoa(idx)
+			if haveUVs && haveNorms {
+				s = s + "/" + s + "/" + s
+			} else if haveUVs {
+				s = s + "/" + s
+			} else if haveNorms {
+				s = s + "//" + s
+			}
+			wr.WriteString(" " + s)
+		}
+		wr.WriteByte('\n')
+	}
+	return wr.Flush()
+}
+
+// only write what changed from the current group
+func writeOBJGroup(wr *bufio.Writer, curr, g *MeshGroup) {
+	if g.Object != curr.Object {
+		fmt.Fprintf(wr, "o %s\n", g.Object)
+		curr.Name = ""
+	}
+	if g.Name != curr.Name {
+		fmt.Fprintf(wr, "g %s\n", g.Name)
+	}
+	if g.Material != curr.Material {
+		fmt.Fprintf(wr, "usemtl %s\n", g.Material)
+	}
+	*curr = *g
+}
+
+// the shortest representation reading back to the same value
+func formatFloat(v float32) string {
+	return strconv.FormatFloat(float64(v), 'g', -1, 32)
+}
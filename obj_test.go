@@ -0,0 +1,158 @@
+package vec32
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+const objCube = `# a cube
+mtllib cube.mtl
+v 0 0 0
+v 0 0 1
+v 0 1 1
+v 0 1 0
+v 1 0 0
+v 1 0 1
+v 1 1 1
+v 1 1 0
+vt 0 0
+vt 1 0
+vt 1 1
+vt 0 1
+vn -1 0 0
+vn 1 0 0
+o cube
+g left
+usemtl red
+f 1/1/1 2/2/1 3/3/1 4/4/1
+g right
+f -4/1/-1 -3/2/-1 \
+  -2/3/-1 -1/4/-1
+usemtl blue
+f 1//1 5//1 6//1
+f 2 6 7
+`
+
+func TestReadOBJ(t *testing.T) {
+	m, err := ReadOBJ(strings.NewReader(objCube))
+	if err != nil {
+		t.Errorf("error on reading OBJ: %s", err.Error())
+		return
+	}
+	// the triangles share positions with the quads, but not uv and normal
+	if len(m.Verts) != 8+6 || m.TriCount() != 6 {
+		t.Errorf("expected 14 verts and 6 tris, got %d and %d", len(m.Verts), m.TriCount())
+		return
+	}
+	if len(m.Normals) != len(m.Verts) || len(m.UVs) != len(m.Verts) || m.Colors != nil {
+		t.Errorf("wrong attributes: %d normals, %d uvs, %d colors",
+			len(m.Normals), len(m.UVs), len(m.Colors))
+	}
+	i1, i2, i3, _ := m.TriVerts(1)
+	if i1 != 0 || i2 != 2 || i3 != 3 {
+		t.Errorf("wrong triangulation: %d %d %d", i1, i2, i3)
+	}
+	i1, i2, i3, _ = m.TriVerts(2)
+	if i1 != 4 || i2 != 5 || i3 != 6 || m.UVs[6] != (Vec2{1, 1}) || m.Normals[6].X != 1 {
+		t.Errorf("negative indices failed: %d %d %d", i1, i2, i3)
+	}
+	i1, i2, i3, _ = m.TriVerts(4)
+	if i1 != 8 || i2 != 9 || i3 != 10 || !m.Verts[10].IsEqual(&m.Verts[5]) {
+		t.Errorf("expected extra vertices, got %d %d %d", i1, i2, i3)
+	}
+	var groups = []MeshGroup{
+		{"cube", "left", "red", 0, 2},
+		{"cube", "right", "red", 2, 4},
+		{"cube", "right", "blue", 4, 6},
+	}
+	if len(m.Groups) != len(groups) {
+		t.Errorf("expected %d groups, got %v", len(groups), m.Groups)
+		return
+	}
+	for i := range groups {
+		if m.Groups[i] != groups[i] {
+			t.Errorf("group %d: expected %v, got %v", i, groups[i], m.Groups[i])
+		}
+	}
+	if len(m.MaterialLibs) != 1 || m.MaterialLibs[0] != "cube.mtl" {
+		t.Errorf("wrong material libraries %v", m.MaterialLibs)
+	}
+}
+
+func TestReadOBJErrors(t *testing.T) {
+	var cases = []struct {
+		inputStr string
+		errorRsp string
+	}{
+		{"", ""},
+		{"v 1 2 3\nv 1 2 3\nf 1 2\n", "line 3: a face must have at least 3 indices"},
+		{"v 1 2 3\nv 1 2 x\n", "line 2: could not convert `x` to float"},
+		{"v 1 2\n", "line 1: expected 3 to 7 values, got 2"},
+		{"vn 1 2 3 4\n", "line 1: expected 3 to 3 values, got 4"},
+		{"v 1 2 3\nf 1 1 2\n", "line 2: vertex index out of range"},
+		{"v 1 2 3\nf 1 1 -2\n", "line 2: vertex index out of range"},
+		{"v 1 2 3\nf 1 1 0\n", "line 2: vertex index out of range"},
+		{"v 1 2 3\nf 1 1 1/2\n", "line 2: vertex index out of range"},
+		{"v 1 2 3\n# comment\nf 1 1 a\n", "line 3: could not convert `a` to int"},
+		{"v 1 2 3\nf 1 1 1/1/1/1\n", "line 2: invalid face vertex `1/1/1/1`"},
+		{"v 1 2 3\nl 1 1\np 1\ns off\nf 1 1 1 # degenerated\n", ""},
+	}
+	for i, tc := range cases {
+		_, err := ReadOBJ(strings.NewReader(tc.inputStr))
+		errString := ""
+		if err != nil {
+			errString = err.Error()
+		}
+		if errString != tc.errorRsp {
+			t.Errorf("tc %d: expected error \"%s\", got \"%s\"", i+1, tc.errorRsp, errString)
+		}
+	}
+}
+
+func TestWriteOBJRoundTrip(t *testing.T) {
+	var files = []string{
+		"paulbourke.net.sample1.ply",
+		"paulbourke.net.sample2.ply",
+		"people.sc.fsu.edu.helix.ply",
+	}
+	for i, file := range files {
+		m, _ := getMesh(t, i, file)
+		if m == nil {
+			continue
+		}
+		var buf bytes.Buffer
+		if err := WriteOBJ(&buf, m); err != nil {
+			t.Errorf("tc %d: error on writing: %s", i, err.Error())
+			continue
+		}
+		m2, err := ReadOBJ(&buf)
+		if err != nil {
+			t.Errorf("tc %d: error on reading back: %s", i, err.Error())
+			continue
+		}
+		testMeshEqual(t, i, m, m2)
+	}
+
+	m, _ := ReadOBJ(strings.NewReader(objCube))
+	var buf bytes.Buffer
+	if err := WriteOBJ(&buf, m); err != nil {
+		t.Errorf("cube: error on writing: %s", err.Error())
+		return
+	}
+	m2, err := ReadOBJ(&buf)
+	if err != nil {
+		t.Errorf("cube: error on reading back: %s", err.Error())
+		return
+	}
+	testMeshEqual(t, len(files), m, m2)
+	if len(m2.Groups) != len(m.Groups) || len(m2.MaterialLibs) != 1 {
+		t.Errorf("cube: groups or materials lost")
+		return
+	}
+	for i := range m.Groups {
+		if m.Groups[i] != m2.Groups[i] {
+			t.Errorf("cube: group %d: expected %v, got %v", i, m.Groups[i], m2.Groups[i])
+		}
+	}
+}
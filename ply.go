@@ -1,24 +1,7 @@
 package vec32
 
 import (
-	"bufio"
-	"bytes"
-	"fmt"
 	"io"
-	"strconv"
-	"strings"
-)
-
-const (
-	typeIgnore = iota
-	typeChar   = iota
-	typeUchar  = iota
-	typeShort  = iota
-	typeUshort = iota
-	typeInt    = iota
-	typeUint   = iota
-	typeFloat  = iota
-	typeDouble = iota
 )
 
 const (
@@ -26,301 +9,286 @@ const (
 	propX      = iota
 	propY      = iota
 	propZ      = iota
+	propNX     = iota
+	propNY     = iota
+	propNZ     = iota
+	propU      = iota
+	propV      = iota
+	propRed    = iota
+	propGreen  = iota
+	propBlue   = iota
+	propAlpha  = iota
 	propCount  = iota
 )
 
-const (
-	elementIgnore = iota
-	elementVert   = iota
-	elementFace   = iota
-)
-
 var propMap = map[string]uint{
-	"x": propX,
-	"y": propY,
-	"z": propZ,
+	"x":         propX,
+	"y":         propY,
+	"z":         propZ,
+	"nx":        propNX,
+	"ny":        propNY,
+	"nz":        propNZ,
+	"s":         propU,
+	"t":         propV,
+	"u":         propU,
+	"v":         propV,
+	"texture_u": propU,
+	"texture_v": propV,
+	"texture_s": propU,
+	"texture_t": propV,
+	"red":       propRed,
+	"green":     propGreen,
+	"blue":      propBlue,
+	"alpha":     propAlpha,
 }
 
-var typeMap = map[string]uint{
-	"char":    typeChar,
-	"uchar":   typeUchar,
-	"short":   typeUshort,
-	"int":     typeInt,
-	"uint":    typeUint,
-	"float":   typeFloat,
-	"float32": typeFloat,
-	"float64": typeDouble,
-	"double":  typeDouble,
+// colors stored as integers are scaled by the maximum of the type
+var colorScale = [...]float32{
+	typeIgnore: 1,
+	typeChar:   1.0 / 127,
+	typeUchar:  1.0 / 255,
+	typeShort:  1.0 / 32767,
+	typeUshort: 1.0 / 65535,
+	typeInt:    1.0 / 2147483647,
+	typeUint:   1.0 / 4294967295,
+	typeFloat:  1,
+	typeDouble: 1,
 }
 
 type meshBuilder struct {
-	rd          *bufio.Reader
-	mesh        *Mesh
-	facesPhase  bool
-	nVerts      int
-	nFaces      int
-	vertProp    []property
-	vertPropIdx int
-	faceProp    []property
-	facePropIdx int
-	haveVerts   bool
-	scanner     *bufio.Scanner
-	currElement uint
-	triIdx      int
+	pr        *PLYReader
+	mesh      *Mesh
+	vertElem  *PLYElement
+	faceElem  *PLYElement
+	indexProp int
 }
 
-type property struct {
-	propType uint
-	propIdx  uint
-	name     string
+// options for reading PLY files
+//
+// With Strict, the mesh is checked by Mesh.Validate() and any problem
+// found is an error.
+type PLYReadOptions struct {
+	Strict bool
 }
 
+func NewPLYReadDefaultOptions() *PLYReadOptions {
+	return &PLYReadOptions{
+		Strict: false,
+	}
+}
+
+// Read a mesh from a PLY file
+//
+// Only the elements vertex and face are used, see NewPLYReader() for
+// reading others.
 func ReadPLY(r io.Reader) (m *Mesh, err error) {
-	rd := bufio.NewReader(r)
+	return ReadPLYWithOptions(r, nil)
+}
+
+// Read a mesh from a PLY file, see ReadPLY()
+//
+// In strict mode the returned ErrorMesh lists the first problems found.
+func ReadPLYWithOptions(r io.Reader, opt *PLYReadOptions) (m *Mesh, err error) {
+	if opt == nil {
+		opt = NewPLYReadDefaultOptions()
+	}
 	mb := meshBuilder{}
-	mb.rd = rd
 	mb.mesh = &Mesh{}
-	mb.vertProp = make([]property, 64)
-	mb.faceProp = make([]property, 64)
-	mb.scanner = bufio.NewScanner(mb.rd)
-	mb.scanner.Split(scanLines)
-	if err = mb.readHeader(); err != nil {
+	if mb.pr, err = NewPLYReader(r); err != nil {
 		return nil, err
 	}
-	mb.scanner.Split(bufio.ScanWords)
-	Info.Printf("Read header, start to read values (%d verts, %d faces)",
-		mb.nVerts, mb.nFaces)
-	mb.mesh.Verts = make([]Vec3, mb.nVerts)
-	mb.mesh.Tris = make([]Triangle, mb.nFaces)
-	if err = mb.readVerts(); err != nil {
+	if err = mb.validateHeader(); err != nil {
 		return nil, err
 	}
-	if err = mb.readFaces(); err != nil {
+	nVerts, nFaces := 0, 0
+	if mb.vertElem != nil {
+		nVerts = mb.vertElem.Count
+	}
+	if mb.faceElem != nil {
+		nFaces = mb.faceElem.Count
+	}
+	Info.Printf("Read header, start to read values (%d verts, %d faces)",
+		nVerts, nFaces)
+	mb.mesh.Verts = make([]Vec3, nVerts)
+	mb.mesh.Indices = make([]uint32, 0, 3*nFaces)
+	mb.allocAttributes()
+	if err = mb.readElements(); err != nil {
 		return nil, err
 	}
+	if opt.Strict {
+		if err = mb.mesh.Validate().Err(); err != nil {
+			return nil, err
+		}
+	}
 	return mb.mesh, nil
 }
 
-func (mb *meshBuilder) readHeader() error {
-	var line string
-	var err error
-	if line, err = mb.nextLine(); err != nil {
-		return err
-	}
-	if line != "ply" {
-		return newErrorMesh("expected file-magic ply\\r")
-	}
-	if line, err = mb.nextLine(); err != nil {
-		return err
-	}
-	if line != "format ascii 1.0" {
-		return newErrorMesh("only format ascii 1.0 supported")
-	}
-	for {
-		line, err = mb.nextLine()
-		if err != nil {
-			return newErrorMesh(err.Error())
-		}
-		if line == "end_header" {
-			return mb.validateHeader()
-		} else if strings.HasPrefix(line, "comment ") {
-			// pass
-		} else if strings.HasPrefix(line, "element vertex ") {
-			if e := mb.readElementVertex(line); e != nil {
-				return e
-			}
-		} else if strings.HasPrefix(line, "element face ") {
-			if e := mb.readFace(line); e != nil {
-				return e
+func (mb *meshBuilder) validateHeader() error {
+	header := mb.pr.Header()
+	mb.vertElem = header.Element("vertex")
+	mb.faceElem = header.Element("face")
+	if mb.vertElem != nil && mb.vertElem.Count > 0 {
+		var haveX, haveY, haveZ bool
+		for _, prop := range mb.vertElem.Properties {
+			if prop.IsList {
+				continue
 			}
-		} else if strings.HasPrefix(line, "element ") {
-			mb.currElement = elementIgnore
-		} else if strings.HasPrefix(line, "property ") {
-			if e := mb.readProperty(line); e != nil {
-				return e
+			switch propMap[prop.Name] {
+			case propX:
+				haveX = true
+			case propY:
+				haveY = true
+			case propZ:
+				haveZ = true
 			}
-		} else {
-			return newErrorMesh("unexpected line in header: " + strings.TrimRight(line, "\r"))
+		}
+		if !(haveX && haveY && haveZ) {
+			return newErrorMesh("invalid vertex definition (missing coordinate)")
 		}
 	}
-}
-
-func (mb *meshBuilder) readFace(line string) error {
-	if n, e := fmt.Sscanf(line, "element face %d\r", &mb.nFaces); e != nil || n != 1 {
-		return newErrorMesh("failed to parse number of faces")
+	if mb.faceElem != nil {
+		mb.indexProp = faceIndexProp(mb.faceElem)
+		if mb.faceElem.Count > 0 && mb.indexProp < 0 {
+			return newErrorMesh("invalid face definition (missing vertex indices)")
+		}
 	}
-	mb.currElement = elementFace
 	return nil
 }
 
-func (mb *meshBuilder) readElementVertex(line string) error {
-	if n, e := fmt.Sscanf(line, "element vertex %d\r", &mb.nVerts); e != nil || n != 1 || mb.nVerts < 0 {
-		return newErrorMesh("failed to parse number of vertices")
+// create the attribute streams of the mesh the vertex properties ask for
+func (mb *meshBuilder) allocAttributes() {
+	if mb.vertElem == nil {
+		return
+	}
+	var have [propCount]bool
+	for _, prop := range mb.vertElem.Properties {
+		if !prop.IsList {
+			have[propMap[prop.Name]] = true
+		}
 	}
-	mb.currElement = elementVert
-	mb.haveVerts = mb.nVerts > 0
-	return nil
-}
-
-func (mb *meshBuilder) readProperty(line string) error {
-	if mb.currElement == elementIgnore {
-		return nil
+	n := mb.vertElem.Count
+	if have[propNX] && have[propNY] && have[propNZ] {
+		mb.mesh.Normals = make([]Vec3, n)
 	}
-	if mb.currElement == elementVert {
-		var propTypeName, name string
-		n, e := fmt.Sscanf(line, "property %s %s", &propTypeName, &name)
-		if n != 2 || e != nil {
-			return newErrorMesh("failed to parse: " + line)
-		}
-		propType, ok := typeMap[propTypeName]
-		if !ok {
-			return newErrorMesh("unknown property type: " + propTypeName)
-		}
-		if !mb.facesPhase {
-			mb.addVertProperty(propType, name)
-		}
-	} else if mb.currElement == elementFace {
-		if !strings.HasPrefix(line, "property list ") {
-			return newErrorMesh("only lists supported for faces")
+	if have[propU] && have[propV] {
+		mb.mesh.UVs = make([]Vec2, n)
+	}
+	if have[propRed] && have[propGreen] && have[propBlue] {
+		mb.mesh.Colors = make([]Color, n)
+		for i := range mb.mesh.Colors {
+			mb.mesh.Colors[i].A = 1
 		}
-		// should be a std-list
 	}
-	return nil
-}
-
-func (mb *meshBuilder) addVertProperty(propType uint, name string) {
-	prop := &mb.vertProp[mb.vertPropIdx]
-	mb.vertPropIdx += 1
-	prop.name = name
-	prop.propType = propType
-	prop.propIdx, _ = propMap[name]
 }
 
-func (mb *meshBuilder) validateHeader() error {
-	if !mb.haveVerts {
-		return nil
-	}
-	var haveX, haveY, haveZ bool
-	for i := 0; i < mb.vertPropIdx; i++ {
-		switch mb.vertProp[i].propIdx {
-		case propX:
-			haveX = true
-		case propY:
-			haveY = true
-		case propZ:
-			haveZ = true
+// get the property holding the vertex indices of a face
+func faceIndexProp(el *PLYElement) int {
+	idx := -1
+	for i, prop := range el.Properties {
+		if !prop.IsList {
+			continue
+		}
+		if prop.Name == "vertex_index" || prop.Name == "vertex_indices" {
+			return i
+		}
+		if idx < 0 {
+			idx = i
 		}
 	}
-	if !(haveX && haveY && haveZ) {
-		return newErrorMesh("invalid vertex definition (missing coordinate)")
-	}
-	return nil
+	return idx
 }
 
-func (mb *meshBuilder) readVerts() error {
-	for i := 0; i < mb.nVerts; i++ {
-		for j := 0; j < mb.vertPropIdx; j++ {
-			var val float32
-			var err error
-			if val, err = mb.nextFloat(); err != nil {
+// read all elements in the order of the header
+//
+// elements after the vertices and faces aren't touched at all
+func (mb *meshBuilder) readElements() error {
+	remaining := 0
+	if mb.vertElem != nil {
+		remaining += 1
+	}
+	if mb.faceElem != nil {
+		remaining += 1
+	}
+	for ; remaining > 0; remaining-- {
+		el := mb.pr.NextElement()
+		for el != mb.vertElem && el != mb.faceElem {
+			if err := mb.pr.SkipElement(); err != nil {
 				return err
 			}
-			mb.addVertProp(i, int(mb.vertProp[j].propIdx), float32(val))
+			el = mb.pr.NextElement()
+		}
+		data, err := mb.pr.ReadElement()
+		if err != nil {
+			return err
+		}
+		if el == mb.vertElem {
+			mb.readVerts(data)
+		} else if err = mb.readFaces(data); err != nil {
+			return err
 		}
 	}
 	return nil
 }
 
-func (mb *meshBuilder) readFaces() error {
-	for i := 0; i < mb.nFaces; i++ {
-		// we only expect lists here
-		if !mb.scanner.Scan() {
-			return newErrorMesh("unexpected end of file")
+func (mb *meshBuilder) readVerts(data *PLYElementData) {
+	for j := range data.Columns {
+		c := &data.Columns[j]
+		propIdx := propMap[c.Property.Name]
+		if c.Property.IsList || propIdx == propIgnore {
+			continue
+		}
+		scale := float32(1)
+		if propIdx >= propRed && propIdx <= propAlpha {
+			scale = colorScale[c.Property.Type]
 		}
-		var cnt int64
-		var err error
-		if cnt, err = strconv.ParseInt(mb.scanner.Text(), 10, 32); err != nil {
-			return newErrorMesh("could not convert `" + mb.scanner.Text() + "` to int")
+		for i := 0; i < data.Element.Count; i++ {
+			mb.addVertProp(i, int(propIdx), float32(c.Float(i))*scale)
 		}
+	}
+}
+
+// add the faces as triangle fans, the quads are kept if all faces are quads
+func (mb *meshBuilder) readFaces(data *PLYElementData) error {
+	if data.Element.Count == 0 {
+		return nil
+	}
+	c := &data.Columns[mb.indexProp]
+	quads := make([]uint32, 0, 4*data.Element.Count)
+	for i := 0; i < data.Element.Count; i++ {
+		cnt := c.ListLen(i)
 		if cnt < 3 {
 			return newErrorMesh("a face must have at least 3 indices")
 		}
-		var p0, p1, p2 int
-		if p0, err = mb.nextInt(); err != nil {
-			return err
-		}
-		if p1, err = mb.nextInt(); err != nil {
-			return err
-		}
-		for j := 2; j < int(cnt); j++ {
-			if p2, err = mb.nextInt(); err != nil {
-				return err
+		if cnt == 4 && quads != nil {
+			for j := 0; j < 4; j++ {
+				quads = append(quads, uint32(c.ListInt(i, j)))
 			}
-			if err = mb.addTriangle(p0, p1, p2); err != nil {
+		} else {
+			quads = nil
+		}
+		p0 := int(c.ListInt(i, 0))
+		p1 := int(c.ListInt(i, 1))
+		for j := 2; j < cnt; j++ {
+			p2 := int(c.ListInt(i, j))
+			if err := mb.addTriangle(p0, p1, p2); err != nil {
 				return err
 			}
 			p1 = p2
 		}
 	}
-	mb.mesh.Tris = mb.mesh.Tris[0:mb.triIdx]
+	mb.mesh.Quads = quads
 	return nil
 }
 
 func (mb *meshBuilder) addTriangle(p0, p1, p2 int) error {
-	if mb.triIdx+1 == cap(mb.mesh.Tris) {
-		tmp := make([]Triangle, 3*cap(mb.mesh.Tris)/2+1)
-		copy(tmp, mb.mesh.Tris)
-		mb.mesh.Tris = tmp
-	}
-	if p0 >= len(mb.mesh.Verts) || p1 >= len(mb.mesh.Verts) || p2 >= len(mb.mesh.Verts) {
+	n := len(mb.mesh.Verts)
+	if p0 < 0 || p0 >= n || p1 < 0 || p1 >= n || p2 < 0 || p2 >= n {
 		return newErrorMesh("vertex index out of range")
 	}
-	mb.mesh.Tris[mb.triIdx].P1 = &mb.mesh.Verts[p0]
-	mb.mesh.Tris[mb.triIdx].P2 = &mb.mesh.Verts[p1]
-	mb.mesh.Tris[mb.triIdx].P3 = &mb.mesh.Verts[p2]
-	mb.triIdx += 1
+	mb.mesh.Indices = append(mb.mesh.Indices, uint32(p0), uint32(p1), uint32(p2))
 	return nil
 }
 
-func (mb *meshBuilder) nextLine() (val string, err error) {
-	if !mb.scanner.Scan() {
-		return "", newErrorMesh("unexpected end of file")
-	}
-	//Trace.Printf(strings.Trim(mb.scanner.Text(), " \t\r\n"))
-	return strings.Trim(mb.scanner.Text(), " \t\r\n"), nil
-}
-
-func (mb *meshBuilder) nextFloat() (val float32, err error) {
-	var token string
-	if token, err = mb.getToken(); err != nil {
-		return 0, err
-	}
-	var result float64
-	if result, err = strconv.ParseFloat(token, 32); err != nil {
-		return 0, newErrorMesh("could not convert `" + token + "` to float")
-	}
-	return float32(result), nil
-}
-
-func (mb *meshBuilder) nextInt() (val int, err error) {
-	var token string
-	if token, err = mb.getToken(); err != nil {
-		return 0, err
-	}
-	var result int64
-	if result, err = strconv.ParseInt(token, 10, 32); err != nil {
-		return 0, newErrorMesh("could not convert `" + token + "` to int")
-	}
-	return int(result), nil
-}
-
-func (mb *meshBuilder) getToken() (token string, err error) {
-	if !mb.scanner.Scan() {
-		return "", newErrorMesh("unexpected end of file")
-	}
-	return mb.scanner.Text(), nil
-}
-
 func (mb *meshBuilder) addVertProp(vertIdx, propIdx int, value float32) {
 	switch propIdx {
 	case propX:
@@ -330,6 +298,34 @@ func (mb *meshBuilder) addVertProp(vertIdx, propIdx int, value float32) {
 	case propZ:
 		mb.mesh.Verts[vertIdx].Z = value
 	}
+	if propIdx >= propNX && propIdx <= propNZ && mb.mesh.Normals != nil {
+		switch propIdx {
+		case propNX:
+			mb.mesh.Normals[vertIdx].X = value
+		case propNY:
+			mb.mesh.Normals[vertIdx].Y = value
+		case propNZ:
+			mb.mesh.Normals[vertIdx].Z = value
+		}
+	} else if propIdx >= propU && propIdx <= propV && mb.mesh.UVs != nil {
+		switch propIdx {
+		case propU:
+			mb.mesh.UVs[vertIdx].X = value
+		case propV:
+			mb.mesh.UVs[vertIdx].Y = value
+		}
+	} else if propIdx >= propRed && propIdx <= propAlpha && mb.mesh.Colors != nil {
+		switch propIdx {
+		case propRed:
+			mb.mesh.Colors[vertIdx].R = value
+		case propGreen:
+			mb.mesh.Colors[vertIdx].G = value
+		case propBlue:
+			mb.mesh.Colors[vertIdx].B = value
+		case propAlpha:
+			mb.mesh.Colors[vertIdx].A = value
+		}
+	}
 }
 
 // Error in mesh creation or something
@@ -359,30 +355,3 @@ func Strcmp(a, b string) int {
 	}
 	return diff
 }
-
-// copied and modified from src/bufio/scan.go
-func scanLines(data []byte, atEOF bool) (advance int, token []byte, err error) {
-	if atEOF && len(data) == 0 {
-		return 0, nil, nil
-	}
-	if i := bytes.IndexByte(data, '\n'); i >= 0 {
-		// We have a full newline-terminated line.
-		return i + 1, dropCR(data[0:i]), nil
-	}
-	if i := bytes.IndexByte(data, '\r'); i >= 0 {
-		return i + 1, data[0:i], nil
-	}
-	// If we're at EOF, we have a final, non-terminated line. Return it.
-	if atEOF {
-		return len(data), dropCR(data), nil
-	}
-	// Request more data.
-	return 0, nil, nil
-}
-
-func dropCR(data []byte) []byte {
-	if len(data) > 0 && data[len(data)-1] == '\r' {
-		return data[0 : len(data)-1]
-	}
-	return data
-}
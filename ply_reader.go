@@ -0,0 +1,591 @@
+package vec32
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+)
+
+const (
+	typeIgnore = iota
+	typeChar   = iota
+	typeUchar  = iota
+	typeShort  = iota
+	typeUshort = iota
+	typeInt    = iota
+	typeUint   = iota
+	typeFloat  = iota
+	typeDouble = iota
+)
+
+// Types of PLY properties
+const (
+	PLY_CHAR   = typeChar
+	PLY_UCHAR  = typeUchar
+	PLY_SHORT  = typeShort
+	PLY_USHORT = typeUshort
+	PLY_INT    = typeInt
+	PLY_UINT   = typeUint
+	PLY_FLOAT  = typeFloat
+	PLY_DOUBLE = typeDouble
+)
+
+const (
+	formatASCII        = iota
+	formatBinaryLittle = iota
+	formatBinaryBig    = iota
+)
+
+var typeMap = map[string]uint{
+	"char":    typeChar,
+	"int8":    typeChar,
+	"uchar":   typeUchar,
+	"uint8":   typeUchar,
+	"short":   typeShort,
+	"int16":   typeShort,
+	"ushort":  typeUshort,
+	"uint16":  typeUshort,
+	"int":     typeInt,
+	"int32":   typeInt,
+	"uint":    typeUint,
+	"uint32":  typeUint,
+	"float":   typeFloat,
+	"float32": typeFloat,
+	"float64": typeDouble,
+	"double":  typeDouble,
+}
+
+// size in bytes of the types in binary files
+var typeSize = [...]int{
+	typeIgnore: 0,
+	typeChar:   1,
+	typeUchar:  1,
+	typeShort:  2,
+	typeUshort: 2,
+	typeInt:    4,
+	typeUint:   4,
+	typeFloat:  4,
+	typeDouble: 8,
+}
+
+var formatMap = map[string]uint{
+	"ascii 1.0":                formatASCII,
+	"binary_little_endian 1.0": formatBinaryLittle,
+	"binary_big_endian 1.0":    formatBinaryBig,
+}
+
+// The header of a PLY file
+type PLYHeader struct {
+	Format   uint
+	Comments []string
+	Elements []PLYElement
+}
+
+// An element of a PLY file (vertex, face, edge, ...)
+type PLYElement struct {
+	Name       string
+	Count      int
+	Properties []PLYProperty
+}
+
+// A property of an element
+//
+// Type is one of PLY_CHAR ... PLY_DOUBLE. For lists it is the type of the
+// items and CountType the type of the length.
+type PLYProperty struct {
+	Name      string
+	Type      uint
+	IsList    bool
+	CountType uint
+}
+
+// Reader for arbitrary elements of PLY files
+//
+// The elements have to be read (or skipped) in the order of the header.
+type PLYReader struct {
+	rd     *bufio.Reader
+	dec    plyDecoder
+	header PLYHeader
+	next   int
+}
+
+// The values of a property for all rows of an element
+//
+// Integer types are stored in Ints, float types in Floats. The items of
+// lists are concatenated, the ones of row i are at Offsets[i]:Offsets[i+1].
+type PLYColumn struct {
+	Property *PLYProperty
+	Ints     []int64
+	Floats   []float64
+	Offsets  []int
+}
+
+// The values of an element, one column per property
+type PLYElementData struct {
+	Element *PLYElement
+	Columns []PLYColumn
+}
+
+// Create a reader and parse the header
+func NewPLYReader(r io.Reader) (*PLYReader, error) {
+	pr := &PLYReader{rd: bufio.NewReader(r)}
+	if err := pr.readHeader(); err != nil {
+		return nil, err
+	}
+	switch pr.header.Format {
+	case formatASCII:
+		pr.dec = newPlyASCIIDecoder(pr.rd)
+	case formatBinaryLittle:
+		pr.dec = &plyBinaryDecoder{rd: pr.rd, order: binary.LittleEndian}
+	default:
+		pr.dec = &plyBinaryDecoder{rd: pr.rd, order: binary.BigEndian}
+	}
+	return pr, nil
+}
+
+// Get the parsed header
+func (pr *PLYReader) Header() *PLYHeader {
+	return &pr.header
+}
+
+// Get the element to be read next, nil if all are read
+func (pr *PLYReader) NextElement() *PLYElement {
+	if pr.next >= len(pr.header.Elements) {
+		return nil
+	}
+	return &pr.header.Elements[pr.next]
+}
+
+// Read all values of the next element
+//
+// returns io.EOF if all elements are read
+func (pr *PLYReader) ReadElement() (*PLYElementData, error) {
+	el := pr.NextElement()
+	if el == nil {
+		return nil, io.EOF
+	}
+	pr.next += 1
+	data := &PLYElementData{Element: el, Columns: make([]PLYColumn, len(el.Properties))}
+	for j := range data.Columns {
+		c := &data.Columns[j]
+		c.Property = &el.Properties[j]
+		if c.Property.IsList {
+			c.Offsets = make([]int, 1, el.Count+1)
+		} else if isFloatType(c.Property.Type) {
+			c.Floats = make([]float64, 0, el.Count)
+		} else {
+			c.Ints = make([]int64, 0, el.Count)
+		}
+	}
+	for i := 0; i < el.Count; i++ {
+		for j := range data.Columns {
+			if err := pr.readValue(&data.Columns[j]); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return data, nil
+}
+
+// Skip all values of the next element
+//
+// returns io.EOF if all elements are read
+func (pr *PLYReader) SkipElement() error {
+	el := pr.NextElement()
+	if el == nil {
+		return io.EOF
+	}
+	pr.next += 1
+	for i := 0; i < el.Count; i++ {
+		for j := range el.Properties {
+			if err := pr.skipValue(&el.Properties[j]); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (pr *PLYReader) readValue(c *PLYColumn) error {
+	prop := c.Property
+	cnt := 1
+	if prop.IsList {
+		var err error
+		if cnt, err = pr.readListLength(prop); err != nil {
+			return err
+		}
+	}
+	for i := 0; i < cnt; i++ {
+		if isFloatType(prop.Type) {
+			val, err := pr.dec.readFloat(prop.Type)
+			if err != nil {
+				return err
+			}
+			c.Floats = append(c.Floats, val)
+		} else {
+			val, err := pr.dec.readInt(prop.Type)
+			if err != nil {
+				return err
+			}
+			c.Ints = append(c.Ints, val)
+		}
+	}
+	if prop.IsList {
+		c.Offsets = append(c.Offsets, len(c.Ints)+len(c.Floats))
+	}
+	return nil
+}
+
+func (pr *PLYReader) skipValue(prop *PLYProperty) error {
+	cnt := 1
+	if prop.IsList {
+		var err error
+		if cnt, err = pr.readListLength(prop); err != nil {
+			return err
+		}
+	}
+	for i := 0; i < cnt; i++ {
+		if err := pr.dec.skip(prop.Type); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (pr *PLYReader) readListLength(prop *PLYProperty) (int, error) {
+	cnt, err := pr.dec.readInt(prop.CountType)
+	if err != nil {
+		return 0, err
+	}
+	if cnt < 0 {
+		return 0, newErrorMesh("negative length of list " + prop.Name)
+	}
+	return int(cnt), nil
+}
+
+func isFloatType(propType uint) bool {
+	return propType == typeFloat || propType == typeDouble
+}
+
+func (pr *PLYReader) readHeader() error {
+	var line string
+	var err error
+	if line, err = pr.nextLine(); err != nil {
+		return err
+	}
+	if line != "ply" {
+		return newErrorMesh("expected file-magic ply\\r")
+	}
+	if line, err = pr.nextLine(); err != nil {
+		return err
+	}
+	if e := pr.readFormat(line); e != nil {
+		return e
+	}
+	for {
+		line, err = pr.nextLine()
+		if err != nil {
+			return newErrorMesh(err.Error())
+		}
+		if line == "end_header" {
+			return nil
+		} else if strings.HasPrefix(line, "comment ") {
+			pr.header.Comments = append(pr.header.Comments, line[len("comment "):])
+		} else if strings.HasPrefix(line, "obj_info ") {
+			// pass
+		} else if strings.HasPrefix(line, "element ") {
+			if e := pr.readElement(line); e != nil {
+				return e
+			}
+		} else if strings.HasPrefix(line, "property ") {
+			if e := pr.readProperty(line); e != nil {
+				return e
+			}
+		} else {
+			return newErrorMesh("unexpected line in header: " + strings.TrimRight(line, "\r"))
+		}
+	}
+}
+
+func (pr *PLYReader) readFormat(line string) error {
+	if !strings.HasPrefix(line, "format ") {
+		return newErrorMesh("expected format definition")
+	}
+	format := strings.Join(strings.Fields(line[len("format "):]), " ")
+	var ok bool
+	if pr.header.Format, ok = formatMap[format]; !ok {
+		return newErrorMesh("unsupported format: " + format)
+	}
+	return nil
+}
+
+func (pr *PLYReader) readElement(line string) error {
+	var el PLYElement
+	n, e := fmt.Sscanf(line, "element %s %d\r", &el.Name, &el.Count)
+	if e != nil || n != 2 || el.Count < 0 {
+		switch el.Name {
+		case "vertex":
+			return newErrorMesh("failed to parse number of vertices")
+		case "face":
+			return newErrorMesh("failed to parse number of faces")
+		}
+		return newErrorMesh("failed to parse: " + line)
+	}
+	pr.header.Elements = append(pr.header.Elements, el)
+	return nil
+}
+
+func (pr *PLYReader) readProperty(line string) error {
+	if len(pr.header.Elements) == 0 {
+		return newErrorMesh("property without element: " + line)
+	}
+	el := &pr.header.Elements[len(pr.header.Elements)-1]
+	var prop PLYProperty
+	var ok bool
+	fields := strings.Fields(line)
+	if len(fields) >= 4 && fields[1] == "list" {
+		if prop.CountType, ok = typeMap[fields[2]]; !ok {
+			return newErrorMesh("unknown property type: " + fields[2])
+		}
+		if prop.Type, ok = typeMap[fields[3]]; !ok {
+			return newErrorMesh("unknown property type: " + fields[3])
+		}
+		if len(fields) > 4 {
+			prop.Name = fields[4]
+		}
+		prop.IsList = true
+	} else if len(fields) == 3 {
+		if prop.Type, ok = typeMap[fields[1]]; !ok {
+			return newErrorMesh("unknown property type: " + fields[1])
+		}
+		prop.Name = fields[2]
+	} else {
+		return newErrorMesh("failed to parse: " + line)
+	}
+	el.Properties = append(el.Properties, prop)
+	return nil
+}
+
+// read a header line, terminated by \n, \r or \r\n
+//
+// the header is read byte by byte, so nothing of a binary body is consumed
+func (pr *PLYReader) nextLine() (val string, err error) {
+	var line []byte
+	for {
+		var c byte
+		if c, err = pr.rd.ReadByte(); err != nil {
+			if len(line) == 0 {
+				return "", newErrorMesh("unexpected end of file")
+			}
+			break
+		}
+		if c == '\n' {
+			break
+		}
+		if c == '\r' {
+			if next, e := pr.rd.Peek(1); e == nil && next[0] == '\n' {
+				pr.rd.ReadByte()
+			}
+			break
+		}
+		line = append(line, c)
+	}
+	return strings.Trim(string(line), " \t\r\n"), nil
+}
+
+// Get the element with the given name, nil if it doesn't exist
+func (h *PLYHeader) Element(name string) *PLYElement {
+	for i := range h.Elements {
+		if h.Elements[i].Name == name {
+			return &h.Elements[i]
+		}
+	}
+	return nil
+}
+
+// Get the index of the property with the given name, -1 if it doesn't exist
+func (el *PLYElement) Property(name string) int {
+	for i := range el.Properties {
+		if el.Properties[i].Name == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// Get the column of the property with the given name, nil if it doesn't exist
+func (d *PLYElementData) Column(name string) *PLYColumn {
+	if i := d.Element.Property(name); i >= 0 {
+		return &d.Columns[i]
+	}
+	return nil
+}
+
+// Get the value at position i of Ints or Floats as integer
+func (c *PLYColumn) intAt(i int) int64 {
+	if c.Ints != nil {
+		return c.Ints[i]
+	}
+	return int64(c.Floats[i])
+}
+
+// Get the value at position i of Ints or Floats as float
+func (c *PLYColumn) floatAt(i int) float64 {
+	if c.Floats != nil {
+		return c.Floats[i]
+	}
+	return float64(c.Ints[i])
+}
+
+// Get the value of a scalar property as integer
+func (c *PLYColumn) Int(row int) int64 {
+	return c.intAt(row)
+}
+
+// Get the value of a scalar property as float
+func (c *PLYColumn) Float(row int) float64 {
+	return c.floatAt(row)
+}
+
+// Get the number of items of a list property
+func (c *PLYColumn) ListLen(row int) int {
+	return c.Offsets[row+1] - c.Offsets[row]
+}
+
+// Get item i of a list property as integer
+func (c *PLYColumn) ListInt(row, i int) int64 {
+	return c.intAt(c.Offsets[row] + i)
+}
+
+// Get item i of a list property as float
+func (c *PLYColumn) ListFloat(row, i int) float64 {
+	return c.floatAt(c.Offsets[row] + i)
+}
+
+// reads the values of the body of a PLY file
+type plyDecoder interface {
+	readFloat(propType uint) (float64, error)
+	readInt(propType uint) (int64, error)
+	skip(propType uint) error
+}
+
+// values separated by whitespace
+type plyASCIIDecoder struct {
+	scanner *bufio.Scanner
+}
+
+func newPlyASCIIDecoder(rd io.Reader) *plyASCIIDecoder {
+	d := &plyASCIIDecoder{scanner: bufio.NewScanner(rd)}
+	d.scanner.Split(bufio.ScanWords)
+	return d
+}
+
+func (d *plyASCIIDecoder) getToken() (token string, err error) {
+	if !d.scanner.Scan() {
+		return "", newErrorMesh("unexpected end of file")
+	}
+	return d.scanner.Text(), nil
+}
+
+func (d *plyASCIIDecoder) readFloat(propType uint) (val float64, err error) {
+	var token string
+	if token, err = d.getToken(); err != nil {
+		return 0, err
+	}
+	bits := 64
+	if propType == typeFloat {
+		bits = 32
+	}
+	if val, err = strconv.ParseFloat(token, bits); err != nil {
+		return 0, newErrorMesh("could not convert `" + token + "` to float")
+	}
+	return val, nil
+}
+
+func (d *plyASCIIDecoder) readInt(propType uint) (val int64, err error) {
+	var token string
+	if token, err = d.getToken(); err != nil {
+		return 0, err
+	}
+	if val, err = strconv.ParseInt(token, 10, 64); err != nil {
+		return 0, newErrorMesh("could not convert `" + token + "` to int")
+	}
+	return val, nil
+}
+
+func (d *plyASCIIDecoder) skip(propType uint) error {
+	_, err := d.getToken()
+	return err
+}
+
+// values of fixed size in the given byte order
+type plyBinaryDecoder struct {
+	rd    *bufio.Reader
+	order binary.ByteOrder
+	buf   [8]byte
+}
+
+func (d *plyBinaryDecoder) read(propType uint) ([]byte, error) {
+	b := d.buf[:typeSize[propType]]
+	if _, err := io.ReadFull(d.rd, b); err != nil {
+		return nil, newErrorMesh("unexpected end of file")
+	}
+	return b, nil
+}
+
+func (d *plyBinaryDecoder) readFloat(propType uint) (float64, error) {
+	b, err := d.read(propType)
+	if err != nil {
+		return 0, err
+	}
+	switch propType {
+	case typeFloat:
+		return float64(math.Float32frombits(d.order.Uint32(b))), nil
+	case typeDouble:
+		return math.Float64frombits(d.order.Uint64(b)), nil
+	}
+	return float64(d.decodeInt(propType, b)), nil
+}
+
+func (d *plyBinaryDecoder) readInt(propType uint) (int64, error) {
+	b, err := d.read(propType)
+	if err != nil {
+		return 0, err
+	}
+	switch propType {
+	case typeFloat:
+		return int64(math.Float32frombits(d.order.Uint32(b))), nil
+	case typeDouble:
+		return int64(math.Float64frombits(d.order.Uint64(b))), nil
+	}
+	return d.decodeInt(propType, b), nil
+}
+
+func (d *plyBinaryDecoder) decodeInt(propType uint, b []byte) int64 {
+	switch propType {
+	case typeChar:
+		return int64(int8(b[0]))
+	case typeUchar:
+		return int64(b[0])
+	case typeShort:
+		return int64(int16(d.order.Uint16(b)))
+	case typeUshort:
+		return int64(d.order.Uint16(b))
+	case typeInt:
+		return int64(int32(d.order.Uint32(b)))
+	case typeUint:
+		return int64(d.order.Uint32(b))
+	}
+	return 0
+}
+
+func (d *plyBinaryDecoder) skip(propType uint) error {
+	if _, err := d.rd.Discard(typeSize[propType]); err != nil {
+		return newErrorMesh("unexpected end of file")
+	}
+	return nil
+}
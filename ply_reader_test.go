@@ -0,0 +1,168 @@
+package vec32
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"os"
+	"testing"
+)
+
+func TestPLYReaderHeader(t *testing.T) {
+	f, err := os.Open("test/ply/paulbourke.net.sample2.ply")
+	if err != nil {
+		t.Errorf("could not open file: %s", err.Error())
+		return
+	}
+	defer f.Close()
+	pr, err := NewPLYReader(f)
+	if err != nil {
+		t.Errorf("error on reading header: %s", err.Error())
+		return
+	}
+	h := pr.Header()
+	if h.Format != PLY_ASCII || len(h.Comments) != 2 || h.Comments[0] != "author: Greg Turk" {
+		t.Errorf("wrong format or comments: %d %v", h.Format, h.Comments)
+	}
+	var exp = []struct {
+		name  string
+		count int
+		props []PLYProperty
+	}{
+		{"vertex", 8, []PLYProperty{
+			{"x", PLY_FLOAT, false, 0},
+			{"y", PLY_FLOAT, false, 0},
+			{"z", PLY_FLOAT, false, 0},
+			{"red", PLY_UCHAR, false, 0},
+			{"green", PLY_UCHAR, false, 0},
+			{"blue", PLY_UCHAR, false, 0},
+		}},
+		{"face", 7, []PLYProperty{
+			{"vertex_index", PLY_INT, true, PLY_UCHAR},
+		}},
+		{"edge", 5, []PLYProperty{
+			{"vertex1", PLY_INT, false, 0},
+			{"vertex2", PLY_INT, false, 0},
+			{"red", PLY_UCHAR, false, 0},
+			{"green", PLY_UCHAR, false, 0},
+			{"blue", PLY_UCHAR, false, 0},
+		}},
+	}
+	if len(h.Elements) != len(exp) {
+		t.Errorf("expected %d elements, got %d", len(exp), len(h.Elements))
+		return
+	}
+	for i, e := range exp {
+		el := &h.Elements[i]
+		if el.Name != e.name || el.Count != e.count || len(el.Properties) != len(e.props) {
+			t.Errorf("element %d: expected %s (%d), got %s (%d)", i, e.name, e.count, el.Name, el.Count)
+			continue
+		}
+		for j := range e.props {
+			if el.Properties[j] != e.props[j] {
+				t.Errorf("element %d: property %d: expected %v, got %v", i, j, e.props[j], el.Properties[j])
+			}
+		}
+	}
+	if h.Element("edge") != &h.Elements[2] || h.Element("material") != nil {
+		t.Errorf("Element() failed")
+	}
+	if h.Elements[2].Property("red") != 2 || h.Elements[2].Property("alpha") != -1 {
+		t.Errorf("Property() failed")
+	}
+}
+
+func TestPLYReaderElements(t *testing.T) {
+	f, err := os.Open("test/ply/paulbourke.net.sample2.ply")
+	if err != nil {
+		t.Errorf("could not open file: %s", err.Error())
+		return
+	}
+	defer f.Close()
+	pr, err := NewPLYReader(f)
+	if err != nil {
+		t.Errorf("error on reading header: %s", err.Error())
+		return
+	}
+	if err = pr.SkipElement(); err != nil {
+		t.Errorf("error on skipping vertices: %s", err.Error())
+		return
+	}
+	faces, err := pr.ReadElement()
+	if err != nil {
+		t.Errorf("error on reading faces: %s", err.Error())
+		return
+	}
+	c := faces.Column("vertex_index")
+	if c == nil || c.ListLen(0) != 3 || c.ListLen(2) != 4 || c.ListInt(2, 1) != 6 || c.ListFloat(6, 3) != 0 {
+		t.Errorf("wrong face indices")
+	}
+	if pr.NextElement().Name != "edge" {
+		t.Errorf("expected edges next")
+	}
+	edges, err := pr.ReadElement()
+	if err != nil {
+		t.Errorf("error on reading edges: %s", err.Error())
+		return
+	}
+	v1, v2, red := edges.Column("vertex1"), edges.Column("vertex2"), edges.Column("red")
+	var exp = [][3]int64{{0, 1, 255}, {1, 2, 255}, {2, 3, 255}, {3, 0, 255}, {2, 0, 0}}
+	for i, e := range exp {
+		if v1.Int(i) != e[0] || v2.Int(i) != e[1] || red.Int(i) != e[2] || red.Float(i) != float64(e[2]) {
+			t.Errorf("edge %d: expected %v, got %d %d %d", i, e, v1.Int(i), v2.Int(i), red.Int(i))
+		}
+	}
+	if pr.NextElement() != nil {
+		t.Errorf("expected no more elements")
+	}
+	if _, err = pr.ReadElement(); err != io.EOF {
+		t.Errorf("expected EOF on reading, got %v", err)
+	}
+	if err = pr.SkipElement(); err != io.EOF {
+		t.Errorf("expected EOF on skipping, got %v", err)
+	}
+}
+
+func TestPLYReaderBinary(t *testing.T) {
+	const header = "element vertex 2\n" +
+		"property float x\n" +
+		"property float y\n" +
+		"property float z\n" +
+		"element face 1\n" +
+		"property list uchar int vertex_indices\n" +
+		"property ushort label\n" +
+		"property list uchar double weights\n" +
+		"end_header\n"
+	values := []interface{}{
+		float32(1), float32(2), float32(3),
+		float32(4), float32(5), float32(6),
+		uint8(3), int32(0), int32(1), int32(1), uint16(65535), uint8(2), 0.25, -1.5,
+	}
+	for _, order := range []binary.ByteOrder{binary.LittleEndian, binary.BigEndian} {
+		pr, err := NewPLYReader(bytes.NewReader(binaryPLY(order, header, values...)))
+		if err != nil {
+			t.Errorf("%s: error on reading header: %s", order, err.Error())
+			continue
+		}
+		verts, err := pr.ReadElement()
+		if err != nil {
+			t.Errorf("%s: error on reading vertices: %s", order, err.Error())
+			continue
+		}
+		if z := verts.Column("z"); z.Floats == nil || z.Float(1) != 6 || z.Int(0) != 3 {
+			t.Errorf("%s: wrong vertices", order)
+		}
+		faces, err := pr.ReadElement()
+		if err != nil {
+			t.Errorf("%s: error on reading faces: %s", order, err.Error())
+			continue
+		}
+		if label := faces.Column("label"); label.Ints == nil || label.Int(0) != 65535 {
+			t.Errorf("%s: wrong label", order)
+		}
+		w := faces.Column("weights")
+		if w.ListLen(0) != 2 || w.ListFloat(0, 0) != 0.25 || w.ListFloat(0, 1) != -1.5 {
+			t.Errorf("%s: wrong weights", order)
+		}
+	}
+}
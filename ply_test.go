@@ -1,6 +1,8 @@
 package vec32
 
 import (
+	"bytes"
+	"encoding/binary"
 	"io"
 	"os"
 	"strings"
@@ -42,7 +44,15 @@ func TestHeaderBasic(t *testing.T) {
 	}{
 		{"", "unexpected end of file"},
 		{"plx\r", "expected file-magic ply\\r"},
-		{"ply\r" + "format ascii 1.1\r", "only format ascii 1.0 supported"},
+		{"ply\r" + "format ascii 1.1\r", "unsupported format: ascii 1.1"},
+		{"ply\r" + "format binary_middle_endian 1.0\r", "unsupported format: binary_middle_endian 1.0"},
+		{"ply\r" + "format binary_little_endian 1.0\r" + headerEnd, ""},
+		{"ply\r" + "format binary_big_endian 1.0\r" + headerEnd, ""},
+		{headerStart + "property float x\r", "property without element: property float x"},
+		{headerStart + "element vertex 1\rproperty foo x\r", "unknown property type: foo"},
+		{headerStart + "element face 1\rproperty list uchar bar\r", "unknown property type: bar"},
+		{headerStart + "element face 1\rproperty uchar red\r" + headerEnd,
+			"invalid face definition (missing vertex indices)"},
 		{headerStart + "foobar baz\r", "unexpected line in header: foobar baz"},
 		{headerStart + headerEnd, ""},
 		{headerStart + "comment foo bar baz\r" + headerEnd, ""},
@@ -127,12 +137,131 @@ func TestFaces(t *testing.T) {
 		if m == nil {
 			continue
 		}
-		if len(m.Tris) != tc.numFaces {
-			t.Errorf("tc %d: expected %d faces, got %d", i+1, tc.numFaces, len(m.Tris))
+		if m.TriCount() != tc.numFaces {
+			t.Errorf("tc %d: expected %d faces, got %d", i+1, tc.numFaces, m.TriCount())
 		}
 	}
 }
 
+func TestBinary(t *testing.T) {
+	const header = "element material 1\n" +
+		"property uchar id\n" +
+		"property list uchar float params\n" +
+		"element vertex 4\n" +
+		"property double x\n" +
+		"property float y\n" +
+		"property ushort flags\n" +
+		"property float z\n" +
+		"element face 2\n" +
+		"property uchar red\n" +
+		"property list uchar uint vertex_indices\n" +
+		"property short label\n" +
+		"end_header\n"
+	values := []interface{}{
+		// material
+		uint8(7), uint8(2), float32(0.5), float32(1.5),
+		// vertices
+		float64(0), float32(0), uint16(1), float32(0),
+		float64(1), float32(0), uint16(2), float32(-1),
+		float64(1), float32(1), uint16(3), float32(0),
+		float64(-0.5), float32(1), uint16(4), float32(2),
+		// faces
+		uint8(255), uint8(3), uint32(0), uint32(1), uint32(2), int16(-1),
+		uint8(0), uint8(4), uint32(0), uint32(1), uint32(2), uint32(3), int16(2),
+	}
+	verts := []Vec3{
+		NewVec3(0, 0, 0),
+		NewVec3(1, 0, -1),
+		NewVec3(1, 1, 0),
+		NewVec3(-0.5, 1, 2),
+	}
+	for _, order := range []binary.ByteOrder{binary.LittleEndian, binary.BigEndian} {
+		data := binaryPLY(order, header, values...)
+		m, err := ReadPLY(bytes.NewReader(data))
+		if err != nil {
+			t.Errorf("%s: error at reading PLY: %s", order, err.Error())
+			continue
+		}
+		if len(m.Verts) != len(verts) {
+			t.Errorf("%s: expected %d verts, got %d", order, len(verts), len(m.Verts))
+			continue
+		}
+		for i := range verts {
+			if !m.Verts[i].IsEqual(&verts[i]) {
+				t.Errorf("%s: vert %d: expected %s, got %s",
+					order, i, verts[i].String(), m.Verts[i].String())
+			}
+		}
+		if m.TriCount() != 3 {
+			t.Errorf("%s: expected 3 faces, got %d", order, m.TriCount())
+			continue
+		}
+		if i1, i2, i3, _ := m.TriVerts(2); i1 != 0 || i2 != 2 || i3 != 3 {
+			t.Errorf("%s: wrong triangulation of the quad", order)
+		}
+
+		// every truncation must be reported
+		for l := len(data) - 1; data[l-1] != '\n'; l-- {
+			if _, err = ReadPLY(bytes.NewReader(data[:l])); err == nil ||
+				err.Error() != "unexpected end of file" {
+				t.Errorf("%s: missing error on file truncated to %d bytes", order, l)
+				break
+			}
+		}
+	}
+}
+
+func TestVertexAttributes(t *testing.T) {
+	const header = headerStart +
+		"element vertex 2\r" +
+		validVertCoord +
+		"property float nx\r" +
+		"property float ny\r" +
+		"property float nz\r" +
+		"property float s\r" +
+		"property float t\r" +
+		"property uchar red\r" +
+		"property uchar green\r" +
+		"property uchar blue\r" +
+		"property uchar alpha\r" +
+		headerEnd
+	m, err := ReadPLY(newReader(header +
+		"0 0 0 0 0 1 0.5 0.25 255 0 51 255\r" +
+		"1 1 1 1 0 0 1 0 0 255 0 0\r"))
+	if err != nil {
+		t.Errorf("error at reading PLY: %s", err.Error())
+		return
+	}
+	if len(m.Normals) != 2 || len(m.UVs) != 2 || len(m.Colors) != 2 {
+		t.Errorf("missing attributes: %d normals, %d uvs, %d colors",
+			len(m.Normals), len(m.UVs), len(m.Colors))
+		return
+	}
+	n := NewVec3(1, 0, 0)
+	if !m.Normals[1].IsEqual(&n) {
+		t.Errorf("wrong normal %s", m.Normals[1].String())
+	}
+	if m.UVs[0] != (Vec2{0.5, 0.25}) {
+		t.Errorf("wrong texture coordinate %s", m.UVs[0].String())
+	}
+	if !colorAlmostEqual(&m.Colors[0], &Color{1, 0, 0.2, 1}) || m.Colors[1] != (Color{0, 1, 0, 0}) {
+		t.Errorf("wrong colors %v %v", m.Colors[0], m.Colors[1])
+	}
+
+	// incomplete attributes are dropped, colors without alpha are opaque
+	m, _ = getMesh(t, 0, "paulbourke.net.sample2.ply")
+	if m == nil {
+		return
+	}
+	if m.Normals != nil || m.UVs != nil || len(m.Colors) != len(m.Verts) {
+		t.Errorf("expected colors only")
+		return
+	}
+	if m.Colors[0] != (Color{1, 0, 0, 1}) || m.Colors[7] != (Color{0, 0, 1, 1}) {
+		t.Errorf("wrong colors %v %v", m.Colors[0], m.Colors[7])
+	}
+}
+
 func TestRealSamples(t *testing.T) {
 	var cases = []struct {
 		file     string
@@ -160,12 +289,28 @@ func TestRealSamples(t *testing.T) {
 		if len(m.Verts) != tc.numVerts {
 			t.Errorf("tc %d: expected %d verts, got %d", i+1, tc.numVerts, len(m.Verts))
 		}
-		if len(m.Tris) != tc.numTris {
-			t.Errorf("tc %d: expected %d faces, got %d", i+1, tc.numTris, len(m.Tris))
+		if m.TriCount() != tc.numTris {
+			t.Errorf("tc %d: expected %d faces, got %d", i+1, tc.numTris, m.TriCount())
 		}
 	}
 }
 
+// create a binary PLY file, the format line is added to the header
+func binaryPLY(order binary.ByteOrder, header string, values ...interface{}) []byte {
+	var buf bytes.Buffer
+	buf.WriteString("ply\n")
+	if order == binary.LittleEndian {
+		buf.WriteString("format binary_little_endian 1.0\n")
+	} else {
+		buf.WriteString("format binary_big_endian 1.0\n")
+	}
+	buf.WriteString(header)
+	for _, v := range values {
+		binary.Write(&buf, order, v)
+	}
+	return buf.Bytes()
+}
+
 func testError(t *testing.T, tc int, mesh, errTest string) *Mesh {
 	m, err := ReadPLY(newReader(mesh))
 	if err == nil && errTest == "" {
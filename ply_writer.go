@@ -0,0 +1,201 @@
+package vec32
+
+import (
+	"bufio"
+	"encoding/binary"
+	"io"
+	"math"
+	"strconv"
+)
+
+// Formats for writing PLY files
+const (
+	PLY_ASCII                = formatASCII
+	PLY_BINARY_LITTLE_ENDIAN = formatBinaryLittle
+	PLY_BINARY_BIG_ENDIAN    = formatBinaryBig
+)
+
+// options for writing PLY files
+type PLYWriteOptions struct {
+	Format   uint
+	Comments []string
+}
+
+func NewPLYDefaultOptions() *PLYWriteOptions {
+	return &PLYWriteOptions{
+		Format: PLY_ASCII,
+	}
+}
+
+type meshWriter struct {
+	wr        *bufio.Writer
+	mesh      *Mesh
+	opt       *PLYWriteOptions
+	order     binary.ByteOrder
+	buf       [16]byte
+	lineStart bool
+}
+
+// Write a mesh as PLY file
+//
+// The vertex indices of the faces are taken from m.Indices, or recovered
+// from the points of the triangles, which have to point into m.Verts.
+func WritePLY(w io.Writer, m *Mesh, opt *PLYWriteOptions) error {
+	if opt == nil {
+		opt = NewPLYDefaultOptions()
+	}
+	mw := meshWriter{wr: bufio.NewWriter(w), mesh: m, opt: opt, lineStart: true}
+	switch opt.Format {
+	case PLY_ASCII:
+	case PLY_BINARY_LITTLE_ENDIAN:
+		mw.order = binary.LittleEndian
+	case PLY_BINARY_BIG_ENDIAN:
+		mw.order = binary.BigEndian
+	default:
+		return newErrorMesh("unsupported format for writing")
+	}
+	if err := m.checkTris(); err != nil {
+		return err
+	}
+	mw.writeHeader()
+	mw.writeVerts()
+	mw.writeFaces()
+	return mw.wr.Flush()
+}
+
+func (mw *meshWriter) writeHeader() {
+	mw.wr.WriteString("ply\n")
+	switch mw.opt.Format {
+	case PLY_ASCII:
+		mw.wr.WriteString("format ascii 1.0\n")
+	case PLY_BINARY_LITTLE_ENDIAN:
+		mw.wr.WriteString("format binary_little_endian 1.0\n")
+	case PLY_BINARY_BIG_ENDIAN:
+		mw.wr.WriteString("format binary_big_endian 1.0\n")
+	}
+	for _, c := range mw.opt.Comments {
+		mw.wr.WriteString("comment " + c + "\n")
+	}
+	mw.wr.WriteString("element vertex " + strconv.Itoa(len(mw.mesh.Verts)) + "\n")
+	mw.wr.WriteString("property float x\n")
+	mw.wr.WriteString("property float y\n")
+	mw.wr.WriteString("property float z\n")
+	if mw.haveNormals() {
+		mw.wr.WriteString("property float nx\n")
+		mw.wr.WriteString("property float ny\n")
+		mw.wr.WriteString("property float nz\n")
+	}
+	if mw.haveUVs() {
+		mw.wr.WriteString("property float s\n")
+		mw.wr.WriteString("property float t\n")
+	}
+	if mw.haveColors() {
+		mw.wr.WriteString("property uchar red\n")
+		mw.wr.WriteString("property uchar green\n")
+		mw.wr.WriteString("property uchar blue\n")
+		mw.wr.WriteString("property uchar alpha\n")
+	}
+	mw.wr.WriteString("element face " + strconv.Itoa(mw.mesh.TriCount()) + "\n")
+	mw.wr.WriteString("property list uchar int vertex_indices\n")
+	mw.wr.WriteString("end_header\n")
+}
+
+func (mw *meshWriter) haveNormals() bool {
+	return len(mw.mesh.Normals) > 0 && len(mw.mesh.Normals) == len(mw.mesh.Verts)
+}
+
+func (mw *meshWriter) haveUVs() bool {
+	return len(mw.mesh.UVs) > 0 && len(mw.mesh.UVs) == len(mw.mesh.Verts)
+}
+
+func (mw *meshWriter) haveColors() bool {
+	return len(mw.mesh.Colors) > 0 && len(mw.mesh.Colors) == len(mw.mesh.Verts)
+}
+
+func (mw *meshWriter) writeVerts() {
+	normals, uvs, colors := mw.haveNormals(), mw.haveUVs(), mw.haveColors()
+	for i := range mw.mesh.Verts {
+		v := &mw.mesh.Verts[i]
+		mw.writeFloat(v.X)
+		mw.writeFloat(v.Y)
+		mw.writeFloat(v.Z)
+		if normals {
+			n := &mw.mesh.Normals[i]
+			mw.writeFloat(n.X)
+			mw.writeFloat(n.Y)
+			mw.writeFloat(n.Z)
+		}
+		if uvs {
+			mw.writeFloat(mw.mesh.UVs[i].X)
+			mw.writeFloat(mw.mesh.UVs[i].Y)
+		}
+		if colors {
+			c := &mw.mesh.Colors[i]
+			mw.writeUchar(colorToUchar(c.R))
+			mw.writeUchar(colorToUchar(c.G))
+			mw.writeUchar(colorToUchar(c.B))
+			mw.writeUchar(colorToUchar(c.A))
+		}
+		mw.endLine()
+	}
+}
+
+func colorToUchar(v float32) uint8 {
+	return uint8(Max(0, Min(1, v))*255 + 0.5)
+}
+
+func (mw *meshWriter) writeFaces() {
+	for i, n := 0, mw.mesh.TriCount(); i < n; i++ {
+		i1, i2, i3, _ := mw.mesh.TriVerts(i)
+		mw.writeUchar(3)
+		mw.writeInt(i1)
+		mw.writeInt(i2)
+		mw.writeInt(i3)
+		mw.endLine()
+	}
+}
+
+// values are separated by a space in ASCII, so start each one with it
+func (mw *meshWriter) separate() {
+	if mw.order == nil && !mw.lineStart {
+		mw.wr.WriteByte(' ')
+	}
+	mw.lineStart = false
+}
+
+func (mw *meshWriter) endLine() {
+	if mw.order == nil {
+		mw.wr.WriteByte('\n')
+	}
+	mw.lineStart = true
+}
+
+// ASCII is written as the shortest representation reading back to the same value
+func (mw *meshWriter) writeFloat(v float32) {
+	mw.separate()
+	if mw.order == nil {
+		mw.wr.Write(strconv.AppendFloat(mw.buf[:0], float64(v), 'g', -1, 32))
+	} else {
+		mw.order.PutUint32(mw.buf[:4], math.Float32bits(v))
+		mw.wr.Write(mw.buf[:4])
+	}
+}
+
+func (mw *meshWriter) writeInt(v int) {
+	mw.separate()
+	if mw.order == nil {
+		mw.wr.Write(strconv.AppendInt(mw.buf[:0], int64(v), 10))
+	} else {
+		mw.order.PutUint32(mw.buf[:4], uint32(v))
+		mw.wr.Write(mw.buf[:4])
+	}
+}
+
+func (mw *meshWriter) writeUchar(v uint8) {
+	mw.separate()
+	if mw.order == nil {
+		mw.wr.Write(strconv.AppendUint(mw.buf[:0], uint64(v), 10))
+	} else {
+		mw.wr.WriteByte(v)
+	}
+}
@@ -0,0 +1,153 @@
+package vec32
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestWritePLYRoundTrip(t *testing.T) {
+	var files = []string{
+		"paulbourke.net.sample1.ply",
+		"paulbourke.net.sample2.ply",
+		"people.sc.fsu.edu.helix.ply",
+		"two_cubes.ply",
+		"two_cubes_2y.ply",
+		"two_cubes_y.ply",
+		"two_cubes_z.ply",
+	}
+	var formats = []uint{PLY_ASCII, PLY_BINARY_LITTLE_ENDIAN, PLY_BINARY_BIG_ENDIAN}
+	for i, file := range files {
+		m, _ := getMesh(t, i, file)
+		if m == nil {
+			continue
+		}
+		for _, format := range formats {
+			var buf bytes.Buffer
+			if err := WritePLY(&buf, m, &PLYWriteOptions{Format: format}); err != nil {
+				t.Errorf("tc %d (%s, format %d): error on writing: %s", i, file, format, err.Error())
+				continue
+			}
+			m2, err := ReadPLY(&buf)
+			if err != nil {
+				t.Errorf("tc %d (%s, format %d): error on reading back: %s", i, file, format, err.Error())
+				continue
+			}
+			testMeshEqual(t, i, m, m2)
+		}
+	}
+}
+
+func TestWritePLYAttributes(t *testing.T) {
+	m := &Mesh{
+		Verts:   []Vec3{NewVec3(0, 0, 0), NewVec3(1, 0, 0), NewVec3(0, 1, 0)},
+		Normals: []Vec3{NewVec3(0, 0, 1), NewVec3(0, 0.6, 0.8), NewVec3(0.6, 0, 0.8)},
+		UVs:     []Vec2{{0, 0}, {1, 0}, {0.25, 0.75}},
+		Colors:  []Color{{1, 0, 0, 1}, {0, 1, 0, 0.2}, {0, 0, 1, 0}},
+	}
+	m.Tris = []Triangle{{&m.Verts[0], &m.Verts[1], &m.Verts[2]}}
+	for _, format := range []uint{PLY_ASCII, PLY_BINARY_LITTLE_ENDIAN} {
+		var buf bytes.Buffer
+		if err := WritePLY(&buf, m, &PLYWriteOptions{Format: format}); err != nil {
+			t.Errorf("format %d: error on writing: %s", format, err.Error())
+			continue
+		}
+		m2, err := ReadPLY(&buf)
+		if err != nil {
+			t.Errorf("format %d: error on reading back: %s", format, err.Error())
+			continue
+		}
+		testMeshEqual(t, int(format), m, m2)
+	}
+}
+
+func TestWritePLYHeader(t *testing.T) {
+	m, _ := getMesh(t, 0, "paulbourke.net.sample1.ply")
+	if m == nil {
+		return
+	}
+	var buf bytes.Buffer
+	opt := NewPLYDefaultOptions()
+	opt.Comments = []string{"written by vec32"}
+	if err := WritePLY(&buf, m, opt); err != nil {
+		t.Errorf("error on writing: %s", err.Error())
+		return
+	}
+	exp := "ply\n" +
+		"format ascii 1.0\n" +
+		"comment written by vec32\n" +
+		"element vertex 8\n" +
+		"property float x\n" +
+		"property float y\n" +
+		"property float z\n" +
+		"element face 12\n" +
+		"property list uchar int vertex_indices\n" +
+		"end_header\n" +
+		"0 0 0\n"
+	if !strings.HasPrefix(buf.String(), exp) {
+		t.Errorf("unexpected header:\n%s", buf.String()[:len(exp)])
+	}
+}
+
+func TestWritePLYErrors(t *testing.T) {
+	p := NewVec3(1, 2, 3)
+	m := &Mesh{Verts: []Vec3{NewVec3(0, 0, 0), NewVec3(1, 0, 0)}}
+	m.Tris = []Triangle{{&m.Verts[0], &m.Verts[1], &p}}
+	var buf bytes.Buffer
+	if err := WritePLY(&buf, m, nil); err == nil ||
+		err.Error() != "triangle 0 does not point into the vertices" {
+		t.Errorf("expected error on foreign vertex, got %v", err)
+	}
+	m.Tris = nil
+	if err := WritePLY(&buf, m, &PLYWriteOptions{Format: 42}); err == nil ||
+		err.Error() != "unsupported format for writing" {
+		t.Errorf("expected error on unknown format, got %v", err)
+	}
+}
+
+func testMeshEqual(t *testing.T, tc int, exp, cur *Mesh) {
+	if len(exp.Verts) != len(cur.Verts) || exp.TriCount() != cur.TriCount() {
+		t.Errorf("tc %d: expected %d verts and %d tris, got %d and %d", tc,
+			len(exp.Verts), exp.TriCount(), len(cur.Verts), cur.TriCount())
+		return
+	}
+	for i := range exp.Verts {
+		if !exp.Verts[i].IsEqual(&cur.Verts[i]) {
+			t.Errorf("tc %d: vert %d differs: expected %s, got %s", tc, i,
+				exp.Verts[i].String(), cur.Verts[i].String())
+			return
+		}
+	}
+	if len(exp.Normals) != len(cur.Normals) || len(exp.UVs) != len(cur.UVs) ||
+		len(exp.Colors) != len(cur.Colors) {
+		t.Errorf("tc %d: attributes differ", tc)
+		return
+	}
+	for i := range exp.Normals {
+		if !exp.Normals[i].IsEqual(&cur.Normals[i]) {
+			t.Errorf("tc %d: normal %d differs", tc, i)
+			return
+		}
+	}
+	for i := range exp.UVs {
+		if exp.UVs[i] != cur.UVs[i] {
+			t.Errorf("tc %d: texture coordinate %d differs", tc, i)
+			return
+		}
+	}
+	for i := range exp.Colors {
+		if !colorAlmostEqual(&exp.Colors[i], &cur.Colors[i]) {
+			t.Errorf("tc %d: color %d differs", tc, i)
+			return
+		}
+	}
+	for i, n := 0, exp.TriCount(); i < n; i++ {
+		e1, e2, e3, _ := exp.TriVerts(i)
+		c1, c2, c3, _ := cur.TriVerts(i)
+		if e1 != c1 || e2 != c2 || e3 != c3 {
+			t.Errorf("tc %d: tri %d differs: expected %d %d %d, got %d %d %d", tc, i,
+				e1, e2, e3, c1, c2, c3)
+			return
+		}
+	}
+}
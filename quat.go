@@ -0,0 +1,263 @@
+package vec32
+
+import (
+	"fmt"
+)
+
+// Create a new quaternion
+func NewQuat(x, y, z, w float32) Quat {
+	return Quat{x, y, z, w}
+}
+
+// Create the identity rotation
+func NewQuatIdentity() Quat {
+	return Quat{0, 0, 0, 1}
+}
+
+// Create a rotation about axis by angle (radians, counter clockwise)
+func NewQuatAxisAngle(axis *Vec3, angle float32) Quat {
+	a := axis.Normalize()
+	s := Sin(angle / 2)
+	return Quat{a.X * s, a.Y * s, a.Z * s, Cos(angle / 2)}
+}
+
+// Create a rotation from euler angles (radians)
+//
+// The rotation about X (roll) is applied first, then about Y (pitch) and
+// last about Z (yaw).
+func NewQuatEuler(roll, pitch, yaw float32) Quat {
+	cr, sr := Cos(roll/2), Sin(roll/2)
+	cp, sp := Cos(pitch/2), Sin(pitch/2)
+	cy, sy := Cos(yaw/2), Sin(yaw/2)
+	return Quat{
+		sr*cp*cy - cr*sp*sy,
+		cr*sp*cy + sr*cp*sy,
+		cr*cp*sy - sr*sp*cy,
+		cr*cp*cy + sr*sp*sy,
+	}
+}
+
+// Create a rotation from a rotation matrix
+func NewQuatFromMat3(m *Mat3) Quat {
+	var q Quat
+	// choose the largest of w, x, y, z to divide by for best precision
+	tr := m[0][0] + m[1][1] + m[2][2]
+	switch {
+	case tr > 0:
+		s := 2 * Sqrt(tr+1)
+		q.W = s / 4
+		q.X = (m[1][2] - m[2][1]) / s
+		q.Y = (m[2][0] - m[0][2]) / s
+		q.Z = (m[0][1] - m[1][0]) / s
+	case m[0][0] > m[1][1] && m[0][0] > m[2][2]:
+		s := 2 * Sqrt(1+m[0][0]-m[1][1]-m[2][2])
+		q.W = (m[1][2] - m[2][1]) / s
+		q.X = s / 4
+		q.Y = (m[1][0] + m[0][1]) / s
+		q.Z = (m[2][0] + m[0][2]) / s
+	case m[1][1] > m[2][2]:
+		s := 2 * Sqrt(1+m[1][1]-m[0][0]-m[2][2])
+		q.W = (m[2][0] - m[0][2]) / s
+		q.X = (m[1][0] + m[0][1]) / s
+		q.Y = s / 4
+		q.Z = (m[2][1] + m[1][2]) / s
+	default:
+		s := 2 * Sqrt(1+m[2][2]-m[0][0]-m[1][1])
+		q.W = (m[0][1] - m[1][0]) / s
+		q.X = (m[2][0] + m[0][2]) / s
+		q.Y = (m[2][1] + m[1][2]) / s
+		q.Z = s / 4
+	}
+	return q
+}
+
+// Create a rotation from the rotational part of a matrix
+func NewQuatFromMat4(m *Mat4) Quat {
+	m3 := m.Mat3()
+	return NewQuatFromMat3(&m3)
+}
+
+// string representation
+func (q *Quat) String() string {
+	return fmt.Sprintf("[%g; %g; %g; %g]", q.X, q.Y, q.Z, q.W)
+}
+
+// The length
+func (q *Quat) Length() float32 {
+	return Sqrt(q.Dot(q))
+}
+
+// Dot product
+func (q *Quat) Dot(q2 *Quat) float32 {
+	return q.X*q2.X + q.Y*q2.Y + q.Z*q2.Z + q.W*q2.W
+}
+
+// Multiply two quaternions, the rotation q2 is applied first
+func (q *Quat) Mul(q2 *Quat) *Quat {
+	r := new(Quat)
+	MulQuat(q, q2, r)
+	return r
+}
+
+// Multiply two quaternions (explicit)
+//
+// c may be the same as a or b
+func MulQuat(a, b, c *Quat) {
+	*c = Quat{
+		a.W*b.X + a.X*b.W + a.Y*b.Z - a.Z*b.Y,
+		a.W*b.Y - a.X*b.Z + a.Y*b.W + a.Z*b.X,
+		a.W*b.Z + a.X*b.Y - a.Y*b.X + a.Z*b.W,
+		a.W*b.W - a.X*b.X - a.Y*b.Y - a.Z*b.Z,
+	}
+}
+
+// Get the conjugated quaternion
+func (q *Quat) Conjugate() *Quat {
+	return &Quat{-q.X, -q.Y, -q.Z, q.W}
+}
+
+// Get the inverse quaternion, the conjugate for unit quaternions
+func (q *Quat) Inverse() *Quat {
+	r := new(Quat)
+	InverseQuat(q, r)
+	return r
+}
+
+// Get the inverse quaternion (explicit)
+func InverseQuat(q, inv *Quat) {
+	n := q.Dot(q)
+	*inv = Quat{-q.X / n, -q.Y / n, -q.Z / n, q.W / n}
+}
+
+// get the normalized quaternion ( |q| == 1 )
+func (q *Quat) Normalize() *Quat {
+	r := new(Quat)
+	NormalizeQuat(q, r)
+	return r
+}
+
+// get the normalized quaternion (explicit)
+func NormalizeQuat(q, out *Quat) {
+	n := q.Length()
+	*out = Quat{q.X / n, q.Y / n, q.Z / n, q.W / n}
+}
+
+// Rotate a vector
+func (q *Quat) Rotate(v *Vec3) *Vec3 {
+	r := new(Vec3)
+	RotateQuat3(q, v, r)
+	return r
+}
+
+// Rotate a vector (explicit)
+//
+// q must be a unit quaternion.
+func RotateQuat3(q *Quat, v, out *Vec3) {
+	// v + 2w(u x v) + 2u x (u x v) with u the imaginary part
+	tx := 2 * (q.Y*v.Z - q.Z*v.Y)
+	ty := 2 * (q.Z*v.X - q.X*v.Z)
+	tz := 2 * (q.X*v.Y - q.Y*v.X)
+	x := v.X + q.W*tx + q.Y*tz - q.Z*ty
+	y := v.Y + q.W*ty + q.Z*tx - q.X*tz
+	z := v.Z + q.W*tz + q.X*ty - q.Y*tx
+	out.X, out.Y, out.Z = x, y, z
+}
+
+// Get the axis and angle (radians) of the rotation
+//
+// The axis of the identity is (1, 0, 0).
+func (q *Quat) AxisAngle() (Vec3, float32) {
+	w := Max(-1, Min(1, q.W))
+	s := Sqrt(1 - w*w)
+	if s < EPS {
+		return NewVec3(1, 0, 0), 2 * Acos(w)
+	}
+	return NewVec3(q.X/s, q.Y/s, q.Z/s), 2 * Acos(w)
+}
+
+// Get the rotation matrix
+func (q *Quat) Mat3() Mat3 {
+	xx, yy, zz := q.X*q.X, q.Y*q.Y, q.Z*q.Z
+	xy, xz, yz := q.X*q.Y, q.X*q.Z, q.Y*q.Z
+	wx, wy, wz := q.W*q.X, q.W*q.Y, q.W*q.Z
+	return Mat3{
+		{1 - 2*(yy+zz), 2 * (xy + wz), 2 * (xz - wy)},
+		{2 * (xy - wz), 1 - 2*(xx+zz), 2 * (yz + wx)},
+		{2 * (xz + wy), 2 * (yz - wx), 1 - 2*(xx+yy)},
+	}
+}
+
+// Get the rotation as affine matrix
+func (q *Quat) Mat4() Mat4 {
+	m := q.Mat3()
+	return NewMat4FromMat3(&m)
+}
+
+// Spherical linear interpolation from q (t=0) to q2 (t=1)
+func (q *Quat) Slerp(q2 *Quat, t float32) *Quat {
+	r := new(Quat)
+	SlerpQuat(q, q2, t, r)
+	return r
+}
+
+// Spherical linear interpolation (explicit)
+//
+// The shorter path is taken, both must be unit quaternions.
+func SlerpQuat(a, b *Quat, t float32, out *Quat) {
+	b2 := *b
+	d := a.Dot(b)
+	if d < 0 {
+		d = -d
+		b2 = Quat{-b.X, -b.Y, -b.Z, -b.W}
+	}
+	if d > 1-1e-4 {
+		// almost the same rotation, sin(theta) gets too small
+		NlerpQuat(a, &b2, t, out)
+		return
+	}
+	theta := Acos(d)
+	s := Sin(theta)
+	sa := Sin((1-t)*theta) / s
+	sb := Sin(t*theta) / s
+	*out = Quat{
+		sa*a.X + sb*b2.X,
+		sa*a.Y + sb*b2.Y,
+		sa*a.Z + sb*b2.Z,
+		sa*a.W + sb*b2.W,
+	}
+}
+
+// Normalized linear interpolation from q (t=0) to q2 (t=1)
+func (q *Quat) Nlerp(q2 *Quat, t float32) *Quat {
+	r := new(Quat)
+	NlerpQuat(q, q2, t, r)
+	return r
+}
+
+// Normalized linear interpolation (explicit)
+//
+// Cheaper than SlerpQuat(), but the angular velocity is not constant.
+func NlerpQuat(a, b *Quat, t float32, out *Quat) {
+	s := float32(1)
+	if a.Dot(b) < 0 {
+		s = -1
+	}
+	r := Quat{
+		(1-t)*a.X + s*t*b.X,
+		(1-t)*a.Y + s*t*b.Y,
+		(1-t)*a.Z + s*t*b.Z,
+		(1-t)*a.W + s*t*b.W,
+	}
+	NormalizeQuat(&r, out)
+}
+
+// Equal
+func (q *Quat) IsEqual(q2 *Quat) bool {
+	return *q == *q2
+}
+
+// AlmostEqual() for all components
+func AlmostEqualQuat(a, b *Quat) bool {
+	return AlmostEqual(a.X, b.X) && AlmostEqual(a.Y, b.Y) &&
+		AlmostEqual(a.Z, b.Z) && AlmostEqual(a.W, b.W)
+}
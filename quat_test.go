@@ -0,0 +1,104 @@
+package vec32
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func testQuat(t *testing.T, name string, exp, cur Quat) {
+	// q and -q are the same rotation
+	if exp.Dot(&cur) < 0 {
+		cur = Quat{-cur.X, -cur.Y, -cur.Z, -cur.W}
+	}
+	d := Quat{exp.X - cur.X, exp.Y - cur.Y, exp.Z - cur.Z, exp.W - cur.W}
+	if d.Length() > 1e-5 {
+		t.Errorf("%s is wrong - expected %s got %s", name, exp.String(), cur.String())
+	}
+}
+
+func randomQuat(rnd *rand.Rand) Quat {
+	axis := NewVec3(rnd.Float32()*2-1, rnd.Float32()*2-1, rnd.Float32()*2-1)
+	return NewQuatAxisAngle(&axis, (rnd.Float32()*2-1)*math.Pi)
+}
+
+func TestQuat(t *testing.T) {
+	q := NewQuat(1, 2, 3, 4)
+	q2 := NewQuat(5, 6, 7, 8)
+	testString(t, "String()", "[1; 2; 3; 4]", q.String())
+	testFloat(t, "Dot()", 70, q.Dot(&q2))
+	testFloat(t, "Length()", float32(math.Sqrt(30)), q.Length())
+	testQuat(t, "Mul()", NewQuat(24, 48, 48, -6), *q.Mul(&q2))
+	testQuat(t, "Conjugate()", NewQuat(-1, -2, -3, 4), *q.Conjugate())
+	id := NewQuatIdentity()
+	testQuat(t, "Inverse()", id, *q.Mul(q.Inverse()))
+	testFloat(t, "Normalize()", 1, q.Normalize().Length())
+	if !q.IsEqual(&q) || q.IsEqual(&q2) || !AlmostEqualQuat(&q, &q) {
+		t.Errorf("IsEqual() is wrong")
+	}
+}
+
+func TestQuatRotate(t *testing.T) {
+	axis := NewVec3(0, 0, 1)
+	q := NewQuatAxisAngle(&axis, math.Pi/2)
+	x := NewVec3(1, 0, 0)
+	testVec3Near(t, "Rotate()", NewVec3(0, 1, 0), *q.Rotate(&x))
+	a, angle := q.AxisAngle()
+	testVec3Near(t, "AxisAngle() axis", axis, a)
+	testFloat(t, "AxisAngle() angle", math.Pi/2, angle)
+
+	// the rotation about X comes first
+	e := NewQuatEuler(math.Pi/2, 0, math.Pi/2)
+	y := NewVec3(0, 1, 0)
+	testVec3Near(t, "Euler()", NewVec3(0, 0, 1), *e.Rotate(&y))
+	testVec3Near(t, "Euler()", NewVec3(0, 1, 0), *e.Rotate(&x))
+
+	rnd := rand.New(rand.NewSource(19))
+	for i := 0; i < 100; i++ {
+		q := randomQuat(rnd)
+		q2 := randomQuat(rnd)
+		v := NewVec3(rnd.Float32()*2-1, rnd.Float32()*2-1, rnd.Float32()*2-1)
+		m := q.Mat3()
+		testVec3Near(t, "Rotate() vs Mat3()", *m.MulVec3(&v), *q.Rotate(&v))
+		m4 := q.Mat4()
+		testVec3Near(t, "Rotate() vs Mat4()", *v.TransformPoint(&m4), *q.Rotate(&v))
+		testQuat(t, "NewQuatFromMat3()", q, NewQuatFromMat3(&m))
+		testQuat(t, "NewQuatFromMat4()", q, NewQuatFromMat4(&m4))
+		testVec3Near(t, "Mul()", *q.Rotate(q2.Rotate(&v)), *q.Mul(&q2).Rotate(&v))
+		testVec3Near(t, "Conjugate()", v, *q.Conjugate().Rotate(q.Rotate(&v)))
+		a, angle := q.AxisAngle()
+		r := NewMat3Rotate(&a, angle)
+		testMat3(t, "AxisAngle()", &r, &m)
+	}
+}
+
+func TestQuatEulerMat(t *testing.T) {
+	roll, pitch, yaw := float32(0.3), float32(-0.7), float32(1.1)
+	x, y, z := NewVec3(1, 0, 0), NewVec3(0, 1, 0), NewVec3(0, 0, 1)
+	rx := NewMat3Rotate(&x, roll)
+	ry := NewMat3Rotate(&y, pitch)
+	rz := NewMat3Rotate(&z, yaw)
+	exp := rz.Mul(ry.Mul(&rx))
+	q := NewQuatEuler(roll, pitch, yaw)
+	m := q.Mat3()
+	testMat3(t, "NewQuatEuler()", exp, &m)
+}
+
+func TestQuatInterpolate(t *testing.T) {
+	axis := NewVec3(0, 1, 0)
+	a := NewQuatIdentity()
+	b := NewQuatAxisAngle(&axis, 2)
+	for _, f := range []float32{0, 0.25, 0.5, 1} {
+		exp := NewQuatAxisAngle(&axis, 2*f)
+		testQuat(t, "Slerp()", exp, *a.Slerp(&b, f))
+	}
+	// the shorter path is taken for -b as well
+	nb := Quat{-b.X, -b.Y, -b.Z, -b.W}
+	testQuat(t, "Slerp(-b)", NewQuatAxisAngle(&axis, 1), *a.Slerp(&nb, 0.5))
+	testQuat(t, "Nlerp()", NewQuatAxisAngle(&axis, 1), *a.Nlerp(&b, 0.5))
+	testQuat(t, "Nlerp(-b)", NewQuatAxisAngle(&axis, 1), *a.Nlerp(&nb, 0.5))
+	testFloat(t, "Nlerp() length", 1, a.Nlerp(&b, 0.3).Length())
+	// nearly equal rotations
+	c := NewQuatAxisAngle(&axis, 1e-4)
+	testQuat(t, "Slerp(close)", NewQuatAxisAngle(&axis, 5e-5), *a.Slerp(&c, 0.5))
+}
@@ -43,18 +43,176 @@ func (v *Vec2) Sub(v2 *Vec2) *Vec2 {
 	return &Vec2{v.X - v2.X, v.Y - v2.Y}
 }
 
+// Substract two vectors (explicit)
+func Sub2(v1, v2, v3 *Vec2) {
+	v3.X = v1.X - v2.X
+	v3.Y = v1.Y - v2.Y
+}
+
 // Multiply two vectors elementwise
 func (v *Vec2) Mul(v2 *Vec2) *Vec2 {
 	return &Vec2{v.X * v2.X, v.Y * v2.Y}
 }
 
+// Multiply two vectors elementwise (explicit)
+func Mul2(v1, v2, v3 *Vec2) {
+	v3.X = v1.X * v2.X
+	v3.Y = v1.Y * v2.Y
+}
+
 // get the normalized vector ( |v| == 1 )
 func (v *Vec2) Normalize() *Vec2 {
 	n := v.Length()
 	return &Vec2{v.X / n, v.Y / n}
 }
 
+// get the normalized vector (explicit)
+func Normalize2(v, out *Vec2) {
+	n := v.Length()
+	out.X = v.X / n
+	out.Y = v.Y / n
+}
+
 // Scale a vector by a factor
 func (v *Vec2) Scale(s float32) *Vec2 {
 	return &Vec2{v.X * s, v.Y * s}
 }
+
+// Scale a vector by a factor (explicit)
+func Scale2(v *Vec2, s float32, out *Vec2) {
+	out.X = v.X * s
+	out.Y = v.Y * s
+}
+
+// Equal
+func (a *Vec2) IsEqual(b *Vec2) bool {
+	return a.X == b.X && a.Y == b.Y
+}
+
+// Almost equal (see AlmostEqual())
+func (a *Vec2) IsAlmostEqual(b *Vec2) bool {
+	return AlmostEqual2(a, b)
+}
+
+// Get the negated vector
+func (v *Vec2) Neg() *Vec2 {
+	return &Vec2{-v.X, -v.Y}
+}
+
+// Negate a vector (explicit)
+func Neg2(v, out *Vec2) {
+	out.X = -v.X
+	out.Y = -v.Y
+}
+
+// Linear interpolation from v (t=0) to v2 (t=1)
+func (v *Vec2) Lerp(v2 *Vec2, t float32) *Vec2 {
+	r := new(Vec2)
+	Lerp2(v, v2, t, r)
+	return r
+}
+
+// Linear interpolation (explicit)
+func Lerp2(v1, v2 *Vec2, t float32, out *Vec2) {
+	out.X = v1.X + (v2.X-v1.X)*t
+	out.Y = v1.Y + (v2.Y-v1.Y)*t
+}
+
+// Componentwise minimum
+func (v *Vec2) Min(v2 *Vec2) *Vec2 {
+	return &Vec2{Min(v.X, v2.X), Min(v.Y, v2.Y)}
+}
+
+// Componentwise minimum (explicit)
+func Min2(v1, v2, v3 *Vec2) {
+	v3.X = Min(v1.X, v2.X)
+	v3.Y = Min(v1.Y, v2.Y)
+}
+
+// Componentwise maximum
+func (v *Vec2) Max(v2 *Vec2) *Vec2 {
+	return &Vec2{Max(v.X, v2.X), Max(v.Y, v2.Y)}
+}
+
+// Componentwise maximum (explicit)
+func Max2(v1, v2, v3 *Vec2) {
+	v3.X = Max(v1.X, v2.X)
+	v3.Y = Max(v1.Y, v2.Y)
+}
+
+// Componentwise absolute value
+func (v *Vec2) Abs() *Vec2 {
+	return &Vec2{Abs(v.X), Abs(v.Y)}
+}
+
+// Componentwise absolute value (explicit)
+func Abs2(v, out *Vec2) {
+	out.X = Abs(v.X)
+	out.Y = Abs(v.Y)
+}
+
+// The euklidian distance of two points
+func (v *Vec2) Distance(v2 *Vec2) float32 {
+	return Sqrt(v.DistanceSq(v2))
+}
+
+// The euklidian distance of two points squared
+func (v *Vec2) DistanceSq(v2 *Vec2) float32 {
+	dx, dy := v.X-v2.X, v.Y-v2.Y
+	return dx*dx + dy*dy
+}
+
+// Reflect the vector at a line with the normal n ( |n| == 1 )
+func (v *Vec2) Reflect(n *Vec2) *Vec2 {
+	r := new(Vec2)
+	Reflect2(v, n, r)
+	return r
+}
+
+// Reflect a vector (explicit)
+func Reflect2(v, n, out *Vec2) {
+	d := 2 * v.Dot(n)
+	out.X = v.X - d*n.X
+	out.Y = v.Y - d*n.Y
+}
+
+// Project the vector onto another one
+func (v *Vec2) Project(onto *Vec2) *Vec2 {
+	r := new(Vec2)
+	Project2(v, onto, r)
+	return r
+}
+
+// Project a vector (explicit)
+func Project2(v, onto, out *Vec2) {
+	s := v.Dot(onto) / onto.LengthSq()
+	out.X = onto.X * s
+	out.Y = onto.Y * s
+}
+
+// The angle between two vectors in radians [0, Pi]
+func (v *Vec2) Angle(v2 *Vec2) float32 {
+	return Abs(Atan2(v.Cross(v2), v.Dot(v2)))
+}
+
+// The perp-dot product, the z component of the cross product in R3
+//
+// It is positive, if v2 is counter clockwise of v.
+func (v *Vec2) Cross(v2 *Vec2) float32 {
+	return v.X*v2.Y - v.Y*v2.X
+}
+
+// Rotate the vector by angle (radians, counter clockwise)
+func (v *Vec2) Rotate(angle float32) *Vec2 {
+	r := new(Vec2)
+	Rotate2(v, angle, r)
+	return r
+}
+
+// Rotate a vector (explicit)
+func Rotate2(v *Vec2, angle float32, out *Vec2) {
+	c, s := Cos(angle), Sin(angle)
+	x := v.X*c - v.Y*s
+	y := v.X*s + v.Y*c
+	out.X, out.Y = x, y
+}
@@ -1,6 +1,7 @@
 package vec32
 
 import (
+	"math"
 	"testing"
 )
 
@@ -25,6 +26,48 @@ func TestVec2(t *testing.T) {
 	testVec2(t, "Mul()", &Vec2{15, 24}, v.Mul(&v2))
 }
 
+func TestVec2Algebra(t *testing.T) {
+	v := Vec2{3, -4}
+	v2 := Vec2{-1, 2}
+	var r Vec2
+	Sub2(&v, &v2, &r)
+	testVec2(t, "Sub2()", &Vec2{4, -6}, &r)
+	Mul2(&v, &v2, &r)
+	testVec2(t, "Mul2()", &Vec2{-3, -8}, &r)
+	Scale2(&v, 2, &r)
+	testVec2(t, "Scale2()", &Vec2{6, -8}, &r)
+	Normalize2(&v, &r)
+	testVec2(t, "Normalize2()", &Vec2{0.6, -0.8}, &r)
+	testVec2(t, "Neg()", &Vec2{-3, 4}, v.Neg())
+	Neg2(&v, &r)
+	testVec2(t, "Neg2()", &Vec2{-3, 4}, &r)
+	testVec2(t, "Lerp()", &Vec2{1, -1}, v.Lerp(&v2, 0.5))
+	testVec2(t, "Min()", &Vec2{-1, -4}, v.Min(&v2))
+	Min2(&v, &v2, &r)
+	testVec2(t, "Min2()", &Vec2{-1, -4}, &r)
+	testVec2(t, "Max()", &Vec2{3, 2}, v.Max(&v2))
+	Max2(&v, &v2, &r)
+	testVec2(t, "Max2()", &Vec2{3, 2}, &r)
+	testVec2(t, "Abs()", &Vec2{3, 4}, v.Abs())
+	testFloat(t, "Distance()", float32(math.Sqrt(16+36)), v.Distance(&v2))
+	testFloat(t, "DistanceSq()", 16+36, v.DistanceSq(&v2))
+	testVec2(t, "Reflect()", &Vec2{3, 4}, v.Reflect(&Vec2{0, 1}))
+	testVec2(t, "Project()", &Vec2{3, 0}, v.Project(&Vec2{2, 0}))
+	testFloat(t, "Cross()", 3*2-(-4)*(-1), v.Cross(&v2))
+	x := Vec2{1, 0}
+	y := Vec2{0, 1}
+	testFloat(t, "Cross() ccw", 1, x.Cross(&y))
+	testFloat(t, "Angle()", math.Pi/2, x.Angle(&y))
+	testFloat(t, "Angle()", math.Pi/2, y.Angle(&x))
+	r = *x.Rotate(math.Pi / 2)
+	if !AlmostEqual(r.Y, 1) || Abs(r.X) > 1e-6 {
+		t.Errorf("Rotate() is wrong - got %s", r.String())
+	}
+	if !v.IsEqual(&v) || v.IsEqual(&v2) || !v.IsAlmostEqual(&Vec2{3, -4}) || !AlmostEqual2(&v, &v) {
+		t.Errorf("IsEqual() is wrong")
+	}
+}
+
 func BenchmarkLengthSq2(b *testing.B) {
 	for i := 0; i < b.N; i++ {
 		v2_1.Dot(&v2_2)
@@ -17,9 +17,6 @@ func (v *Vec3) Length() float32 {
 	return LengthR3(v)
 }
 
-// the euklidian length
-func LengthR3(v *Vec3) float32
-
 func lengthR3(v *Vec3) float32 {
 	return Sqrt(v.X*v.X + v.Y*v.Y + v.Z*v.Z)
 }
@@ -39,9 +36,9 @@ func (v *Vec3) Dot(v2 *Vec3) float32 {
 	return v.X*v2.X + v.Y*v2.Y + v.Z*v2.Z
 }
 
-// Dot product
-func DotR3(v *Vec3) float32 {
-	return v.X*v2.X + v.Y*v2.Y + v.Z*v2.Z
+// Dot product (explicit)
+func DotR3(v1, v2 *Vec3) float32 {
+	return v1.X*v2.X + v1.Y*v2.Y + v1.Z*v2.Z
 }
 
 // Add two vectors
@@ -49,9 +46,6 @@ func (v *Vec3) Add(v2 *Vec3) *Vec3 {
 	return &Vec3{v.X + v2.X, v.Y + v2.Y, v.Z + v2.Z, 0}
 }
 
-// Add two vectors (explicit)
-func Add3(v1, v2, v3 *Vec3)
-
 func add3(v1, v2, v3 *Vec3) {
 	v3.X = v1.X + v2.X
 	v3.Y = v1.Y + v2.Y
@@ -75,23 +69,45 @@ func (v *Vec3) Mul(v2 *Vec3) *Vec3 {
 	return &Vec3{v.X * v2.X, v.Y * v2.Y, v.Z * v2.Z, 0}
 }
 
+// Multiply two vectors elementwise (explicit)
+func Mul3(v1, v2, v3 *Vec3) {
+	v3.X = v1.X * v2.X
+	v3.Y = v1.Y * v2.Y
+	v3.Z = v1.Z * v2.Z
+}
+
 // get the normalized vector ( |v| == 1 )
 func (v *Vec3) Normalize() *Vec3 {
 	n := v.Length()
 	return &Vec3{v.X / n, v.Y / n, v.Z / n, 0}
 }
 
+// get the normalized vector (explicit)
+func Normalize3(v, out *Vec3) {
+	n := v.Length()
+	out.X = v.X / n
+	out.Y = v.Y / n
+	out.Z = v.Z / n
+}
+
 // Scale a vector by a factor
 func (v *Vec3) Scale(s float32) *Vec3 {
 	return &Vec3{v.X * s, v.Y * s, v.Z * s, 0}
 }
 
+// Scale a vector by a factor (explicit)
+func Scale3(v *Vec3, s float32, out *Vec3) {
+	out.X = v.X * s
+	out.Y = v.Y * s
+	out.Z = v.Z * s
+}
+
 // Cross-Product
 func (a *Vec3) Cross(b *Vec3) *Vec3 {
 	return &Vec3{a.Y*b.Z - a.Z*b.Y, a.Z*b.X - a.X*b.Z, a.X*b.Y - a.Y*b.X, 0}
 }
 
-// Cross-Product (implicit)
+// Cross-Product (explicit)
 func Cross3(a, b, c *Vec3) {
 	c.X = a.Y*b.Z - a.Z*b.Y
 	c.Y = a.Z*b.X - a.X*b.Z
@@ -103,7 +119,12 @@ func (bb *OrthoBox) Add(bb2 *OrthoBox) {
 	orthoBoxAdd(bb, bb2)
 }
 
-func OrthoBoxAdd(bb1, bb2 *OrthoBox)
+// Check if two boxes overlap, touching counts
+func (bb *OrthoBox) Overlaps(bb2 *OrthoBox) bool {
+	return bb.P0.X <= bb2.P1.X && bb2.P0.X <= bb.P1.X &&
+		bb.P0.Y <= bb2.P1.Y && bb2.P0.Y <= bb.P1.Y &&
+		bb.P0.Z <= bb2.P1.Z && bb2.P0.Z <= bb.P1.Z
+}
 
 func orthoBoxAdd(bb1, bb2 *OrthoBox) {
 	bb1.P0.X = Min(bb1.P0.X, bb2.P0.X)
@@ -119,5 +140,185 @@ func (a *Vec3) IsEqual(b *Vec3) bool {
 	return a.X == b.X && a.Y == b.Y && a.Z == b.Z
 }
 
-// for testing -- just return
-func doNop()
+// Almost equal (see AlmostEqual())
+func (a *Vec3) IsAlmostEqual(b *Vec3) bool {
+	return AlmostEqual3(a, b)
+}
+
+// Get the negated vector
+func (v *Vec3) Neg() *Vec3 {
+	return &Vec3{-v.X, -v.Y, -v.Z, 0}
+}
+
+// Negate a vector (explicit)
+func Neg3(v, out *Vec3) {
+	out.X = -v.X
+	out.Y = -v.Y
+	out.Z = -v.Z
+}
+
+// Linear interpolation from v (t=0) to v2 (t=1)
+func (v *Vec3) Lerp(v2 *Vec3, t float32) *Vec3 {
+	r := new(Vec3)
+	Lerp3(v, v2, t, r)
+	return r
+}
+
+// Linear interpolation (explicit)
+func Lerp3(v1, v2 *Vec3, t float32, out *Vec3) {
+	out.X = v1.X + (v2.X-v1.X)*t
+	out.Y = v1.Y + (v2.Y-v1.Y)*t
+	out.Z = v1.Z + (v2.Z-v1.Z)*t
+}
+
+// Componentwise minimum
+func (v *Vec3) Min(v2 *Vec3) *Vec3 {
+	return &Vec3{Min(v.X, v2.X), Min(v.Y, v2.Y), Min(v.Z, v2.Z), 0}
+}
+
+// Componentwise minimum (explicit)
+func Min3(v1, v2, v3 *Vec3) {
+	v3.X = Min(v1.X, v2.X)
+	v3.Y = Min(v1.Y, v2.Y)
+	v3.Z = Min(v1.Z, v2.Z)
+}
+
+// Componentwise maximum
+func (v *Vec3) Max(v2 *Vec3) *Vec3 {
+	return &Vec3{Max(v.X, v2.X), Max(v.Y, v2.Y), Max(v.Z, v2.Z), 0}
+}
+
+// Componentwise maximum (explicit)
+func Max3(v1, v2, v3 *Vec3) {
+	v3.X = Max(v1.X, v2.X)
+	v3.Y = Max(v1.Y, v2.Y)
+	v3.Z = Max(v1.Z, v2.Z)
+}
+
+// Componentwise absolute value
+func (v *Vec3) Abs() *Vec3 {
+	return &Vec3{Abs(v.X), Abs(v.Y), Abs(v.Z), 0}
+}
+
+// Componentwise absolute value (explicit)
+func Abs3(v, out *Vec3) {
+	out.X = Abs(v.X)
+	out.Y = Abs(v.Y)
+	out.Z = Abs(v.Z)
+}
+
+// The euklidian distance of two points
+func (v *Vec3) Distance(v2 *Vec3) float32 {
+	return Sqrt(v.DistanceSq(v2))
+}
+
+// The euklidian distance of two points squared
+func (v *Vec3) DistanceSq(v2 *Vec3) float32 {
+	dx, dy, dz := v.X-v2.X, v.Y-v2.Y, v.Z-v2.Z
+	return dx*dx + dy*dy + dz*dz
+}
+
+// Reflect the vector at a plane with the normal n ( |n| == 1 )
+func (v *Vec3) Reflect(n *Vec3) *Vec3 {
+	r := new(Vec3)
+	Reflect3(v, n, r)
+	return r
+}
+
+// Reflect a vector (explicit)
+func Reflect3(v, n, out *Vec3) {
+	d := 2 * v.Dot(n)
+	out.X = v.X - d*n.X
+	out.Y = v.Y - d*n.Y
+	out.Z = v.Z - d*n.Z
+}
+
+// Project the vector onto another one
+func (v *Vec3) Project(onto *Vec3) *Vec3 {
+	r := new(Vec3)
+	Project3(v, onto, r)
+	return r
+}
+
+// Project a vector (explicit)
+func Project3(v, onto, out *Vec3) {
+	s := v.Dot(onto) / onto.LengthSq()
+	out.X = onto.X * s
+	out.Y = onto.Y * s
+	out.Z = onto.Z * s
+}
+
+// The angle between two vectors in radians [0, Pi]
+func (v *Vec3) Angle(v2 *Vec3) float32 {
+	var c Vec3
+	Cross3(v, v2, &c)
+	return Atan2(c.Length(), v.Dot(v2))
+}
+
+// Rotate the vector about axis by angle (radians, counter clockwise)
+func (v *Vec3) Rotate(axis *Vec3, angle float32) *Vec3 {
+	r := new(Vec3)
+	Rotate3(v, axis, angle, r)
+	return r
+}
+
+// Rotate a vector (explicit)
+//
+// see Rodrigues' rotation formula, axis does not need to be normalized
+func Rotate3(v, axis *Vec3, angle float32, out *Vec3) {
+	var k, kxv Vec3
+	Normalize3(axis, &k)
+	Cross3(&k, v, &kxv)
+	c, s := Cos(angle), Sin(angle)
+	d := k.Dot(v) * (1 - c)
+	x := v.X*c + kxv.X*s + k.X*d
+	y := v.Y*c + kxv.Y*s + k.Y*d
+	z := v.Z*c + kxv.Z*s + k.Z*d
+	out.X, out.Y, out.Z = x, y, z
+}
+
+// Value semantics
+//
+// The methods below take and return Vec3 by value. Nothing escapes to the
+// heap, so the compiler can keep the vectors in registers.
+
+// Sum of two vectors
+func (v Vec3) Plus(v2 Vec3) Vec3 {
+	return Vec3{v.X + v2.X, v.Y + v2.Y, v.Z + v2.Z, 0}
+}
+
+// Difference of two vectors
+func (v Vec3) Minus(v2 Vec3) Vec3 {
+	return Vec3{v.X - v2.X, v.Y - v2.Y, v.Z - v2.Z, 0}
+}
+
+// Elementwise product of two vectors
+func (v Vec3) Times(v2 Vec3) Vec3 {
+	return Vec3{v.X * v2.X, v.Y * v2.Y, v.Z * v2.Z, 0}
+}
+
+// The vector scaled by a factor
+func (v Vec3) Scaled(s float32) Vec3 {
+	return Vec3{v.X * s, v.Y * s, v.Z * s, 0}
+}
+
+// The vector with opposite direction
+func (v Vec3) Negated() Vec3 {
+	return Vec3{-v.X, -v.Y, -v.Z, 0}
+}
+
+// The normalized vector ( |v| == 1 )
+func (v Vec3) Normalized() Vec3 {
+	n := Sqrt(v.X*v.X + v.Y*v.Y + v.Z*v.Z)
+	return Vec3{v.X / n, v.Y / n, v.Z / n, 0}
+}
+
+// Cross-Product
+func (a Vec3) Crossed(b Vec3) Vec3 {
+	return Vec3{a.Y*b.Z - a.Z*b.Y, a.Z*b.X - a.X*b.Z, a.X*b.Y - a.Y*b.X, 0}
+}
+
+// Dot product
+func (v Vec3) Inner(v2 Vec3) float32 {
+	return v.X*v2.X + v.Y*v2.Y + v.Z*v2.Z
+}
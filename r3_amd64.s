@@ -1,36 +1,117 @@
+//go:build !purego
+
+// SSE versions of the functions declared in r3_asm.go, the padding of
+// Vec3 allows to load a vector into one register
 
 TEXT ·LengthR3(SB),7,$0-12
-	MOVD        v+0(FP), BP
-    MOVUPS      (BP), X0
-    MULPS       X0, X0
-    MOVLHPS     X0, X2
-    MOVHLPS     X0, X2
-    ADDPS       X2, X0
-    MOVUPS      X0, X1
-    SHUFPS      $0x03, X1, X1
-    ADDSS       X1, X0
-	SQRTSS      X0, X0
-	MOVSS       X0, ret+8(FP)
-    RET 
+	MOVQ	v+0(FP), AX
+	MOVUPS	(AX), X0
+	MULPS	X0, X0
+	MOVAPS	X0, X1
+	MOVAPS	X0, X2
+	SHUFPS	$0x55, X1, X1
+	SHUFPS	$0xaa, X2, X2
+	ADDSS	X1, X0
+	ADDSS	X2, X0
+	SQRTSS	X0, X0
+	MOVSS	X0, ret+8(FP)
+	RET
 
 TEXT ·Add3(SB),7,$0-24
-	MOVD	v1+0(FP),BP
-	MOVUPS	(BP), X0
-	MOVD	v2+8(FP), BP
-	ADDPS	(BP), X0
-	MOVD	v3+16(FP), BP
-	MOVUPS	X0, (BP)
+	MOVQ	v1+0(FP), AX
+	MOVQ	v2+8(FP), BX
+	MOVQ	v3+16(FP), CX
+	MOVUPS	(AX), X0
+	MOVUPS	(BX), X1
+	ADDPS	X1, X0
+	MOVUPS	X0, (CX)
 	RET
 
 TEXT ·OrthoBoxAdd(SB),7,$0-16
-	MOVD	bb2+8(FP), 	BP
-	MOVUPS  (BP),      	X0
-	MOVUPS  16(BP),   	X1
-	MOVD	bb1+0(FP), 	BP
-	MINPS	(BP),       X0
-	MAXPS	16(BP),    	X1
+	MOVQ	bb1+0(FP), AX
+	MOVQ	bb2+8(FP), BX
+	MOVUPS	(AX), X0
+	MOVUPS	16(AX), X1
+	MOVUPS	(BX), X2
+	MOVUPS	16(BX), X3
+	MINPS	X2, X0
+	MAXPS	X3, X1
+	MOVUPS	X0, (AX)
+	MOVUPS	X1, 16(AX)
 	RET
 
 TEXT ·doNop(SB),0,$0-0
 	RET
 
+
+// slab test, see orthoBoxIntersect() - NaN lanes are masked out via CMPPS
+TEXT ·OrthoBoxIntersect(SB),7,$0-32
+	MOVQ	bb+0(FP), AX
+	MOVQ	p0+8(FP), BX
+	MOVQ	inv+16(FP), CX
+	MOVUPS	(BX), X2
+	MOVUPS	(CX), X3
+	MOVUPS	(AX), X0
+	MOVUPS	16(AX), X1
+	SUBPS	X2, X0
+	SUBPS	X2, X1
+	MULPS	X3, X0
+	MULPS	X3, X1
+	MOVAPS	X0, X4
+	CMPPS	X1, X4, $7
+	MOVAPS	X0, X2
+	MINPS	X1, X2
+	MAXPS	X1, X0
+	MOVL	$0xff800000, DX
+	MOVQ	DX, X6
+	SHUFPS	$0x00, X6, X6
+	MOVL	$0x7f800000, DX
+	MOVQ	DX, X7
+	SHUFPS	$0x00, X7, X7
+	ANDPS	X4, X2
+	ANDPS	X4, X0
+	MOVAPS	X4, X5
+	ANDNPS	X6, X4
+	ANDNPS	X7, X5
+	ORPS	X4, X2
+	ORPS	X5, X0
+	MOVAPS	X2, X4
+	MOVAPS	X2, X5
+	SHUFPS	$0x55, X4, X4
+	SHUFPS	$0xaa, X5, X5
+	MAXSS	X4, X2
+	MAXSS	X5, X2
+	MOVSS	X2, tmin+24(FP)
+	MOVAPS	X0, X4
+	MOVAPS	X0, X5
+	SHUFPS	$0x55, X4, X4
+	SHUFPS	$0xaa, X5, X5
+	MINSS	X4, X0
+	MINSS	X5, X0
+	MOVSS	X0, tmax+28(FP)
+	RET
+
+// columns of the matrix are scaled by the broadcasted components and summed up
+TEXT ·TransformPoint3(SB),7,$0-24
+	MOVQ	m+0(FP), AX
+	MOVQ	v+8(FP), BX
+	MOVQ	out+16(FP), CX
+	MOVSS	(BX), X0
+	MOVSS	4(BX), X1
+	MOVSS	8(BX), X2
+	SHUFPS	$0x00, X0, X0
+	SHUFPS	$0x00, X1, X1
+	SHUFPS	$0x00, X2, X2
+	MOVUPS	(AX), X3
+	MOVUPS	16(AX), X4
+	MOVUPS	32(AX), X5
+	MOVUPS	48(AX), X6
+	MULPS	X3, X0
+	MULPS	X4, X1
+	MULPS	X5, X2
+	ADDPS	X1, X0
+	ADDPS	X2, X0
+	ADDPS	X6, X0
+	MOVUPS	X0, (CX)
+	MOVL	$0, 12(CX)
+	RET
@@ -0,0 +1,94 @@
+//go:build !purego
+
+// NEON versions of the functions declared in r3_asm.go
+//
+// The Go assembler lacks most of the floating point vector instructions,
+// they are encoded by WORD with the instruction as comment.
+
+TEXT ·LengthR3(SB),7,$0-12
+	MOVD	v+0(FP), R0
+	VLD1	(R0), [V0.S4]
+	WORD	$0x6e20dc00	// FMUL V0.4S, V0.4S, V0.4S
+	WORD	$0x6e20d401	// FADDP V1.4S, V0.4S, V0.4S
+	WORD	$0x7e30d821	// FADDP S1, V1.2S
+	FSQRTS	F1, F1
+	FMOVS	F1, ret+8(FP)
+	RET
+
+TEXT ·Add3(SB),7,$0-24
+	MOVD	v1+0(FP), R0
+	MOVD	v2+8(FP), R1
+	MOVD	v3+16(FP), R2
+	VLD1	(R0), [V0.S4]
+	VLD1	(R1), [V1.S4]
+	WORD	$0x4e21d400	// FADD V0.4S, V0.4S, V1.4S
+	VST1	[V0.S4], (R2)
+	RET
+
+TEXT ·OrthoBoxAdd(SB),7,$0-16
+	MOVD	bb1+0(FP), R0
+	MOVD	bb2+8(FP), R1
+	VLD1	(R0), [V0.S4, V1.S4]
+	VLD1	(R1), [V2.S4, V3.S4]
+	WORD	$0x4ea2f400	// FMIN V0.4S, V0.4S, V2.4S
+	WORD	$0x4e23f421	// FMAX V1.4S, V1.4S, V3.4S
+	VST1	[V0.S4, V1.S4], (R0)
+	RET
+
+TEXT ·doNop(SB),0,$0-0
+	RET
+
+// slab test, see orthoBoxIntersect() - NaN lanes are masked out via FCMEQ
+TEXT ·OrthoBoxIntersect(SB),7,$0-32
+	MOVD	bb+0(FP), R0
+	MOVD	p0+8(FP), R1
+	MOVD	inv+16(FP), R2
+	VLD1	(R0), [V0.S4, V1.S4]
+	VLD1	(R1), [V2.S4]
+	VLD1	(R2), [V3.S4]
+	WORD	$0x4ea2d400	// FSUB V0.4S, V0.4S, V2.4S
+	WORD	$0x4ea2d421	// FSUB V1.4S, V1.4S, V2.4S
+	WORD	$0x6e23dc00	// FMUL V0.4S, V0.4S, V3.4S
+	WORD	$0x6e23dc21	// FMUL V1.4S, V1.4S, V3.4S
+	WORD	$0x4e20e404	// FCMEQ V4.4S, V0.4S, V0.4S
+	WORD	$0x4e21e425	// FCMEQ V5.4S, V1.4S, V1.4S
+	VAND	V5.B16, V4.B16, V4.B16
+	WORD	$0x4ea1f406	// FMIN V6.4S, V0.4S, V1.4S
+	WORD	$0x4e21f407	// FMAX V7.4S, V0.4S, V1.4S
+	MOVW	$0xff800000, R3
+	VDUP	R3, V16.S4
+	MOVW	$0x7f800000, R3
+	VDUP	R3, V17.S4
+	VBIT	V4.B16, V6.B16, V16.B16
+	VBIT	V4.B16, V7.B16, V17.B16
+	VDUP	V16.S[1], V18.S4
+	VDUP	V16.S[2], V19.S4
+	FMAXS	F18, F16, F16
+	FMAXS	F19, F16, F16
+	FMOVS	F16, tmin+24(FP)
+	VDUP	V17.S[1], V18.S4
+	VDUP	V17.S[2], V19.S4
+	FMINS	F18, F17, F17
+	FMINS	F19, F17, F17
+	FMOVS	F17, tmax+28(FP)
+	RET
+
+// columns of the matrix are scaled by the broadcasted components and summed up
+TEXT ·TransformPoint3(SB),7,$0-24
+	MOVD	m+0(FP), R0
+	MOVD	v+8(FP), R1
+	MOVD	out+16(FP), R2
+	VLD1	(R0), [V0.S4, V1.S4, V2.S4, V3.S4]
+	VLD1	(R1), [V4.S4]
+	VDUP	V4.S[0], V5.S4
+	VDUP	V4.S[1], V6.S4
+	VDUP	V4.S[2], V7.S4
+	WORD	$0x6e25dc05	// FMUL V5.4S, V0.4S, V5.4S
+	WORD	$0x6e26dc26	// FMUL V6.4S, V1.4S, V6.4S
+	WORD	$0x4e26d4a5	// FADD V5.4S, V5.4S, V6.4S
+	WORD	$0x6e27dc47	// FMUL V7.4S, V2.4S, V7.4S
+	WORD	$0x4e27d4a5	// FADD V5.4S, V5.4S, V7.4S
+	WORD	$0x4e23d4a5	// FADD V5.4S, V5.4S, V3.4S
+	VMOV	ZR, V5.S[3]
+	VST1	[V5.S4], (R2)
+	RET
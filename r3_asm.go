@@ -0,0 +1,37 @@
+//go:build (amd64 || arm64) && !purego
+
+package vec32
+
+// Functions implemented in assembly (r3_amd64.s, r3_arm64.s), see
+// r3_noasm.go for the Go versions used on other platforms
+
+// the euklidian length
+//
+//go:noescape
+func LengthR3(v *Vec3) float32
+
+// Add two vectors (explicit)
+//
+//go:noescape
+func Add3(v1, v2, v3 *Vec3)
+
+// Join two boxes, adding the second to the first (explicit)
+//
+//go:noescape
+func OrthoBoxAdd(bb1, bb2 *OrthoBox)
+
+// ray-box-intersection (explicit)
+//
+// p0 is the origin of the ray, inv the reciprocal of its direction.
+// Returns the same as RayInv.IntersectOrthoBox()
+//
+//go:noescape
+func OrthoBoxIntersect(bb *OrthoBox, p0, inv *Vec3) (tmin, tmax float32)
+
+// Transform a point (explicit)
+//
+//go:noescape
+func TransformPoint3(m *Mat4, v, out *Vec3)
+
+// for testing -- just return
+func doNop()
@@ -0,0 +1,37 @@
+//go:build !(amd64 || arm64) || purego
+
+package vec32
+
+// Go versions of the functions implemented in assembly, used on platforms
+// without assembly or if built with the purego tag
+
+// the euklidian length
+func LengthR3(v *Vec3) float32 {
+	return lengthR3(v)
+}
+
+// Add two vectors (explicit)
+func Add3(v1, v2, v3 *Vec3) {
+	add3(v1, v2, v3)
+}
+
+// Join two boxes, adding the second to the first (explicit)
+func OrthoBoxAdd(bb1, bb2 *OrthoBox) {
+	orthoBoxAdd(bb1, bb2)
+}
+
+// ray-box-intersection (explicit)
+//
+// p0 is the origin of the ray, inv the reciprocal of its direction.
+// Returns the same as RayInv.IntersectOrthoBox()
+func OrthoBoxIntersect(bb *OrthoBox, p0, inv *Vec3) (tmin, tmax float32) {
+	return orthoBoxIntersect(bb, p0, inv)
+}
+
+// Transform a point (explicit)
+func TransformPoint3(m *Mat4, v, out *Vec3) {
+	transformPoint3(m, v, out)
+}
+
+// for testing -- just return
+func doNop() {}
@@ -41,6 +41,77 @@ func TestEqual(t *testing.T) {
 
 }
 
+func TestVec3Algebra(t *testing.T) {
+	v := NewVec3(3, -4, 12)
+	v2 := NewVec3(-1, 2, 0)
+	var r Vec3
+	testFloat(t, "DotR3()", -11, DotR3(&v, &v2))
+	Mul3(&v, &v2, &r)
+	testVec3(t, "Mul3()", NewVec3(-3, -8, 0), r)
+	Scale3(&v, 2, &r)
+	testVec3(t, "Scale3()", NewVec3(6, -8, 24), r)
+	Normalize3(&v, &r)
+	testVec3(t, "Normalize3()", NewVec3(3.0/13, -4.0/13, 12.0/13), r)
+	testVec3(t, "Neg()", NewVec3(-3, 4, -12), *v.Neg())
+	Neg3(&v, &r)
+	testVec3(t, "Neg3()", NewVec3(-3, 4, -12), r)
+	testVec3(t, "Lerp()", NewVec3(1, -1, 6), *v.Lerp(&v2, 0.5))
+	testVec3(t, "Min()", NewVec3(-1, -4, 0), *v.Min(&v2))
+	Min3(&v, &v2, &r)
+	testVec3(t, "Min3()", NewVec3(-1, -4, 0), r)
+	testVec3(t, "Max()", NewVec3(3, 2, 12), *v.Max(&v2))
+	Max3(&v, &v2, &r)
+	testVec3(t, "Max3()", NewVec3(3, 2, 12), r)
+	testVec3(t, "Abs()", NewVec3(3, 4, 12), *v.Abs())
+	Abs3(&v, &r)
+	testVec3(t, "Abs3()", NewVec3(3, 4, 12), r)
+	testFloat(t, "Distance()", float32(math.Sqrt(16+36+144)), v.Distance(&v2))
+	testFloat(t, "DistanceSq()", 16+36+144, v.DistanceSq(&v2))
+	n := NewVec3(0, 0, 1)
+	testVec3(t, "Reflect()", NewVec3(3, -4, -12), *v.Reflect(&n))
+	onto := NewVec3(0, 2, 0)
+	testVec3(t, "Project()", NewVec3(0, -4, 0), *v.Project(&onto))
+	x, y := NewVec3(1, 0, 0), NewVec3(0, 1, 0)
+	testFloat(t, "Angle()", math.Pi/2, x.Angle(&y))
+	testFloat(t, "Angle()", 0, x.Angle(&x))
+	testVec3Near(t, "Rotate()", y, *x.Rotate(&Vec3{Z: 2}, math.Pi/2))
+	axis := NewVec3(1, 1, 1)
+	q := NewQuatAxisAngle(&axis, 0.7)
+	testVec3Near(t, "Rotate() vs Quat", *q.Rotate(&v), *v.Rotate(&axis, 0.7))
+	if !v.IsAlmostEqual(&v) || v.IsAlmostEqual(&v2) {
+		t.Errorf("IsAlmostEqual() is wrong")
+	}
+}
+
+func TestVec3Value(t *testing.T) {
+	v := NewVec3(3, 4, 5)
+	v2 := NewVec3(5, 6, 7)
+	testVec3(t, "Plus()", *v.Add(&v2), v.Plus(v2))
+	testVec3(t, "Minus()", *v.Sub(&v2), v.Minus(v2))
+	testVec3(t, "Times()", *v.Mul(&v2), v.Times(v2))
+	testVec3(t, "Scaled()", *v.Scale(2), v.Scaled(2))
+	testVec3(t, "Negated()", *v.Neg(), v.Negated())
+	testVec3(t, "Normalized()", *v.Normalize(), v.Normalized())
+	testVec3(t, "Crossed()", *v.Cross(&v2), v.Crossed(v2))
+	testFloat(t, "Inner()", v.Dot(&v2), v.Inner(v2))
+}
+
+func TestVec3ValueAllocs(t *testing.T) {
+	p0 := NewVec3(1, 2, 3)
+	p1 := NewVec3(4, 6, 8)
+	var r Ray
+	var out Vec3
+	allocs := testing.AllocsPerRun(100, func() {
+		r.P0 = p0
+		r.N = p1.Minus(p0).Normalized()
+		r.At(2, &out)
+	})
+	if allocs != 0 {
+		t.Errorf("expected no allocations, got %f", allocs)
+	}
+	testVec3(t, "At()", NewVec3(1+2*3/Sqrt(50), 2+2*4/Sqrt(50), 3+2*5/Sqrt(50)), out)
+}
+
 func TestCross(t *testing.T) {
 	c := NewVec3(5*7-6*6, 5*6-4*7, 4*6-5*5)
 	testVec3(t, "crossProduct", c, *v3_1.Cross(&v3_2))
@@ -64,6 +135,55 @@ func BenchmarkR3AddEGeneric(b *testing.B) {
 	}
 }
 
+func BenchmarkR3Plus(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		v3_3 = v3_1.Plus(v3_2)
+	}
+}
+
+func BenchmarkR3Normalize(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		v3_3 = *v3_1.Normalize()
+	}
+}
+
+func BenchmarkR3Normalized(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		v3_3 = v3_1.Normalized()
+	}
+}
+
+func BenchmarkR3Cross(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		v3_3 = *v3_1.Cross(&v3_2)
+	}
+}
+
+func BenchmarkR3CrossE(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		Cross3(&v3_1, &v3_2, &v3_3)
+	}
+}
+
+func BenchmarkR3Crossed(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		v3_3 = v3_1.Crossed(v3_2)
+	}
+}
+
+func BenchmarkR3NewRay(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		NewRay(&v3_1, &v3_2)
+	}
+}
+
+func BenchmarkR3RayAt(b *testing.B) {
+	r := NewRay(&v3_1, &v3_2)
+	for i := 0; i < b.N; i++ {
+		r.At(2, &v3_3)
+	}
+}
+
 func BenchmarkR3LengthSq(b *testing.B) {
 	v := NewVec3(3, 4, 5)
 	for i := 0; i < b.N; i++ {
@@ -87,7 +207,7 @@ func BenchmarkR3LengthSqIndirect(b *testing.B) {
 func BenchmarkR3Dot(b *testing.B) {
 	v := NewVec3(3, 4, 5)
 	for i := 0; i < b.N; i++ {
-		DotR3(&v)
+		DotR3(&v, &v3_1)
 	}
 }
 
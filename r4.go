@@ -0,0 +1,112 @@
+package vec32
+
+import (
+	"fmt"
+)
+
+// Create a new Vector
+func NewVec4(x, y, z, w float32) Vec4 {
+	return Vec4{x, y, z, w}
+}
+
+// Create a vector in homogeneous coordinates, w = 1 for points, 0 for directions
+func NewVec4FromVec3(v *Vec3, w float32) Vec4 {
+	return Vec4{v.X, v.Y, v.Z, w}
+}
+
+// Dimension of the vector
+func (v *Vec4) Dim() int { return 4 }
+
+// The euklidian length
+func (v *Vec4) Length() float32 {
+	return Sqrt(v.LengthSq())
+}
+
+// The euklidian length squared
+func (v *Vec4) LengthSq() float32 {
+	return v.X*v.X + v.Y*v.Y + v.Z*v.Z + v.W*v.W
+}
+
+// string representation (octave style)
+func (v *Vec4) String() string {
+	return fmt.Sprintf("[%g; %g; %g; %g]", v.X, v.Y, v.Z, v.W)
+}
+
+// Dot product
+func (v *Vec4) Dot(v2 *Vec4) float32 {
+	return v.X*v2.X + v.Y*v2.Y + v.Z*v2.Z + v.W*v2.W
+}
+
+// Add two vectors
+func (v *Vec4) Add(v2 *Vec4) *Vec4 {
+	return &Vec4{v.X + v2.X, v.Y + v2.Y, v.Z + v2.Z, v.W + v2.W}
+}
+
+// Add two vectors (explicit)
+func Add4(v1, v2, v3 *Vec4) {
+	v3.X = v1.X + v2.X
+	v3.Y = v1.Y + v2.Y
+	v3.Z = v1.Z + v2.Z
+	v3.W = v1.W + v2.W
+}
+
+// Substract two vectors
+func (v *Vec4) Sub(v2 *Vec4) *Vec4 {
+	return &Vec4{v.X - v2.X, v.Y - v2.Y, v.Z - v2.Z, v.W - v2.W}
+}
+
+// Substract two vectors (explicit)
+func Sub4(v1, v2, v3 *Vec4) {
+	v3.X = v1.X - v2.X
+	v3.Y = v1.Y - v2.Y
+	v3.Z = v1.Z - v2.Z
+	v3.W = v1.W - v2.W
+}
+
+// Multiply two vectors elementwise
+func (v *Vec4) Mul(v2 *Vec4) *Vec4 {
+	return &Vec4{v.X * v2.X, v.Y * v2.Y, v.Z * v2.Z, v.W * v2.W}
+}
+
+// get the normalized vector ( |v| == 1 )
+func (v *Vec4) Normalize() *Vec4 {
+	n := v.Length()
+	return &Vec4{v.X / n, v.Y / n, v.Z / n, v.W / n}
+}
+
+// Scale a vector by a factor
+func (v *Vec4) Scale(s float32) *Vec4 {
+	return &Vec4{v.X * s, v.Y * s, v.Z * s, v.W * s}
+}
+
+// Equal
+func (a *Vec4) IsEqual(b *Vec4) bool {
+	return *a == *b
+}
+
+// Get the point in R3 by the perspective divide
+//
+// Directions (w == 0) are returned unchanged.
+func (v *Vec4) Vec3() Vec3 {
+	if v.W == 0 || v.W == 1 {
+		return Vec3{v.X, v.Y, v.Z, 0}
+	}
+	s := 1 / v.W
+	return Vec3{v.X * s, v.Y * s, v.Z * s, 0}
+}
+
+// Multiply the matrix with a vector
+func (m *Mat4) MulVec4(v *Vec4) *Vec4 {
+	r := new(Vec4)
+	MulMat4Vec4(m, v, r)
+	return r
+}
+
+// Multiply the matrix with a vector (explicit)
+func MulMat4Vec4(m *Mat4, v, out *Vec4) {
+	x, y, z, w := v.X, v.Y, v.Z, v.W
+	out.X = m[0][0]*x + m[1][0]*y + m[2][0]*z + m[3][0]*w
+	out.Y = m[0][1]*x + m[1][1]*y + m[2][1]*z + m[3][1]*w
+	out.Z = m[0][2]*x + m[1][2]*y + m[2][2]*z + m[3][2]*w
+	out.W = m[0][3]*x + m[1][3]*y + m[2][3]*z + m[3][3]*w
+}
@@ -0,0 +1,58 @@
+package vec32
+
+import (
+	"math"
+	"testing"
+)
+
+func testVec4(t *testing.T, name string, exp, cur *Vec4) {
+	if !AlmostEqual4(exp, cur) {
+		t.Errorf("%s is wrong - expected %s got %s", name, exp.String(), cur.String())
+	}
+}
+
+func TestVec4(t *testing.T) {
+	v := NewVec4(1, 2, 3, 4)
+	v2 := NewVec4(5, 6, 7, 8)
+	n := float32(math.Sqrt(30))
+	testFloat(t, "Dimension", 4, float32(v.Dim()))
+	testFloat(t, "Length", n, v.Length())
+	testFloat(t, "LengthSq", 30, v.LengthSq())
+	testString(t, "String()", "[1; 2; 3; 4]", v.String())
+	testFloat(t, "Dot()", 70, v.Dot(&v2))
+	testVec4(t, "Add()", &Vec4{6, 8, 10, 12}, v.Add(&v2))
+	testVec4(t, "Sub()", &Vec4{-4, -4, -4, -4}, v.Sub(&v2))
+	testVec4(t, "Mul()", &Vec4{5, 12, 21, 32}, v.Mul(&v2))
+	testVec4(t, "Scale()", &Vec4{2, 4, 6, 8}, v.Scale(2))
+	testVec4(t, "Normalize()", &Vec4{1 / n, 2 / n, 3 / n, 4 / n}, v.Normalize())
+	var r Vec4
+	Add4(&v, &v2, &r)
+	testVec4(t, "Add4()", &Vec4{6, 8, 10, 12}, &r)
+	Sub4(&v, &v2, &r)
+	testVec4(t, "Sub4()", &Vec4{-4, -4, -4, -4}, &r)
+	if !v.IsEqual(&v) || v.IsEqual(&v2) {
+		t.Errorf("IsEqual() is wrong")
+	}
+}
+
+func TestVec4Homogeneous(t *testing.T) {
+	p := NewVec3(1, 2, 3)
+	h := NewVec4FromVec3(&p, 1)
+	testVec3(t, "Vec3()", p, h.Vec3())
+	h = *h.Scale(2)
+	testVec3(t, "Vec3() divide", p, h.Vec3())
+	d := NewVec4FromVec3(&p, 0)
+	testVec3(t, "Vec3() direction", p, d.Vec3())
+
+	tr := NewMat4Translate(&Vec3{X: 1, Y: 1, Z: 1})
+	h = NewVec4FromVec3(&p, 1)
+	testVec4(t, "MulVec4() point", &Vec4{2, 3, 4, 1}, tr.MulVec4(&h))
+	testVec4(t, "MulVec4() direction", &d, tr.MulVec4(&d))
+
+	proj := NewMat4Perspective(math.Pi/2, 1, 1, 10)
+	far := NewVec4(0, 0, -10, 1)
+	clip := proj.MulVec4(&far)
+	testFloat(t, "perspective w", 10, clip.W)
+	ndc := clip.Vec3()
+	testFloat(t, "perspective z", 1, ndc.Z)
+}
@@ -4,13 +4,13 @@ package vec32
 func NewRay(p0, p1 *Vec3) *Ray {
 	r := new(Ray)
 	r.P0 = *p0
-	r.N = *p1.Sub(p0).Normalize()
+	r.N = p1.Minus(*p0).Normalized()
 	return r
 }
 
 // Where will the ray be at value t
 func (r *Ray) At(t float32, v *Vec3) {
-	Add3(&r.P0, r.N.Scale(t), v)
+	*v = r.P0.Plus(r.N.Scaled(t))
 }
 
 // ray-triangle-intersection by Möller–Trumbore
@@ -51,3 +51,105 @@ func (r *Ray) Intersect(tri *Triangle, i *Intersection) float32 {
 		return Inf(1)
 	}
 }
+
+// A ray prepared for intersecting boxes
+//
+// Holds the reciprocal of the direction and its sign bits, so they only
+// have to be calculated once per ray.
+type RayInv struct {
+	Ray
+	Inv  Vec3
+	Sign [3]int
+}
+
+// Precompute the inverse direction of the ray
+//
+// Components of N being zero lead to +/-inf in Inv, which is handled by
+// the box intersection.
+func (r *Ray) Inverse(ri *RayInv) {
+	ri.Ray = *r
+	ri.Inv = NewVec3(1/r.N.X, 1/r.N.Y, 1/r.N.Z)
+	ri.Sign[0] = signBit(ri.Inv.X)
+	ri.Sign[1] = signBit(ri.Inv.Y)
+	ri.Sign[2] = signBit(ri.Inv.Z)
+}
+
+func signBit(v float32) int {
+	if v < 0 {
+		return 1
+	}
+	return 0
+}
+
+// ray-box-intersection by the slab method
+//
+// returns the values of t where the ray enters and leaves the box. The box
+// is missed if tmin > tmax. Negative values are behind the origin of the
+// ray, so the caller has to clip the range to its needs.
+func (r *Ray) IntersectOrthoBox(bb *OrthoBox) (tmin, tmax float32) {
+	var ri RayInv
+	r.Inverse(&ri)
+	return ri.IntersectOrthoBox(bb)
+}
+
+// ray-box-intersection by the slab method (Williams et al.)
+//
+// Like Ray.IntersectOrthoBox(), but uses the precomputed values. If the
+// origin lies on a slab of an axis the ray is parallel to, the result
+// would be NaN - these axis are ignored, so touching counts as hit.
+func (ri *RayInv) IntersectOrthoBox(bb *OrthoBox) (tmin, tmax float32) {
+	bounds := [2]*Vec3{&bb.P0, &bb.P1}
+	tmin = INF_NEG
+	tmax = INF
+
+	// comparisons against NaN are false, so NaN will never shrink the range
+	t0 := (bounds[ri.Sign[0]].X - ri.P0.X) * ri.Inv.X
+	t1 := (bounds[1-ri.Sign[0]].X - ri.P0.X) * ri.Inv.X
+	if t0 > tmin {
+		tmin = t0
+	}
+	if t1 < tmax {
+		tmax = t1
+	}
+	t0 = (bounds[ri.Sign[1]].Y - ri.P0.Y) * ri.Inv.Y
+	t1 = (bounds[1-ri.Sign[1]].Y - ri.P0.Y) * ri.Inv.Y
+	if t0 > tmin {
+		tmin = t0
+	}
+	if t1 < tmax {
+		tmax = t1
+	}
+	t0 = (bounds[ri.Sign[2]].Z - ri.P0.Z) * ri.Inv.Z
+	t1 = (bounds[1-ri.Sign[2]].Z - ri.P0.Z) * ri.Inv.Z
+	if t0 > tmin {
+		tmin = t0
+	}
+	if t1 < tmax {
+		tmax = t1
+	}
+	return
+}
+
+func orthoBoxIntersect(bb *OrthoBox, p0, inv *Vec3) (tmin, tmax float32) {
+	tmin = INF_NEG
+	tmax = INF
+	t0 := (bb.P0.X - p0.X) * inv.X
+	t1 := (bb.P1.X - p0.X) * inv.X
+	if t0 == t0 && t1 == t1 {
+		tmin = Max(tmin, Min(t0, t1))
+		tmax = Min(tmax, Max(t0, t1))
+	}
+	t0 = (bb.P0.Y - p0.Y) * inv.Y
+	t1 = (bb.P1.Y - p0.Y) * inv.Y
+	if t0 == t0 && t1 == t1 {
+		tmin = Max(tmin, Min(t0, t1))
+		tmax = Min(tmax, Max(t0, t1))
+	}
+	t0 = (bb.P0.Z - p0.Z) * inv.Z
+	t1 = (bb.P1.Z - p0.Z) * inv.Z
+	if t0 == t0 && t1 == t1 {
+		tmin = Max(tmin, Min(t0, t1))
+		tmax = Min(tmax, Max(t0, t1))
+	}
+	return
+}
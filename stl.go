@@ -0,0 +1,283 @@
+package vec32
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// size of the header and of a triangle in binary STL files
+const (
+	stlHeaderSize   = 80
+	stlTriangleSize = 50
+)
+
+// options for writing STL files
+type STLWriteOptions struct {
+	Binary bool
+	// name of the solid (ASCII) or content of the header (binary)
+	Name string
+}
+
+func NewSTLDefaultOptions() *STLWriteOptions {
+	return &STLWriteOptions{
+		Binary: true,
+		Name:   "vec32",
+	}
+}
+
+type stlBuilder struct {
+	mesh    *Mesh
+	vertMap map[Vec3]int
+	indices []int
+	lineNo  int
+}
+
+// Read a mesh from an ASCII or binary STL file
+//
+// The format is detected by the content and, if r is an io.Seeker or has
+// a Len() like bytes.Buffer, by the size. Equal vertices of the triangles
+// are welded, so triangles share the points in Mesh.Verts. The normals of
+// the facets are ignored. The solids of ASCII files are kept as Mesh.Groups.
+func ReadSTL(r io.Reader) (*Mesh, error) {
+	size := readerSize(r)
+	rd := bufio.NewReader(r)
+	sb := stlBuilder{mesh: &Mesh{}, vertMap: make(map[Vec3]int)}
+	var err error
+	if isASCIISTL(rd, size) {
+		err = sb.readASCII(rd)
+	} else {
+		err = sb.readBinary(rd)
+	}
+	if err != nil {
+		return nil, err
+	}
+	sb.finish()
+	return sb.mesh, nil
+}
+
+// the number of bytes left in r, -1 if unknown
+func readerSize(r io.Reader) int64 {
+	switch r := r.(type) {
+	case interface{ Len() int }:
+		return int64(r.Len())
+	case io.Seeker:
+		pos, err := r.Seek(0, io.SeekCurrent)
+		if err != nil {
+			return -1
+		}
+		end, err := r.Seek(0, io.SeekEnd)
+		if err != nil {
+			return -1
+		}
+		if _, err = r.Seek(pos, io.SeekStart); err != nil {
+			return -1
+		}
+		return end - pos
+	}
+	return -1
+}
+
+// binary files may start with "solid" as well, so a size matching the
+// triangle count of the header is binary, otherwise look for ASCII keywords
+func isASCIISTL(rd *bufio.Reader, size int64) bool {
+	head, _ := rd.Peek(512)
+	if size >= stlHeaderSize+4 && len(head) >= stlHeaderSize+4 {
+		n := binary.LittleEndian.Uint32(head[stlHeaderSize:])
+		if size == stlHeaderSize+4+stlTriangleSize*int64(n) {
+			return false
+		}
+	}
+	head = bytes.TrimLeft(head, " \t\r\n")
+	if !bytes.HasPrefix(head, []byte("solid")) {
+		return false
+	}
+	return bytes.Contains(head, []byte("facet")) || bytes.Contains(head, []byte("endsolid"))
+}
+
+func (sb *stlBuilder) errorf(format string, args ...interface{}) error {
+	return newErrorMesh(fmt.Sprintf("line %d: ", sb.lineNo) + fmt.Sprintf(format, args...))
+}
+
+func (sb *stlBuilder) readBinary(rd *bufio.Reader) error {
+	var buf [stlTriangleSize]byte
+	if _, err := io.ReadFull(rd, buf[:4]); err != nil {
+		return newErrorMesh("unexpected end of file")
+	}
+	if _, err := rd.Discard(stlHeaderSize - 4); err != nil {
+		return newErrorMesh("unexpected end of file")
+	}
+	if _, err := io.ReadFull(rd, buf[:4]); err != nil {
+		return newErrorMesh("unexpected end of file")
+	}
+	n := binary.LittleEndian.Uint32(buf[:4])
+	Info.Printf("Read STL header, start to read %d triangles", n)
+	for i := uint32(0); i < n; i++ {
+		if _, err := io.ReadFull(rd, buf[:]); err != nil {
+			return newErrorMesh("unexpected end of file")
+		}
+		// skip the normal, ignore the attribute byte count
+		for j := 0; j < 3; j++ {
+			off := 12 + 12*j
+			v := NewVec3(
+				math.Float32frombits(binary.LittleEndian.Uint32(buf[off:])),
+				math.Float32frombits(binary.LittleEndian.Uint32(buf[off+4:])),
+				math.Float32frombits(binary.LittleEndian.Uint32(buf[off+8:])))
+			sb.indices = append(sb.indices, sb.weld(&v))
+		}
+	}
+	return nil
+}
+
+func (sb *stlBuilder) readASCII(rd io.Reader) error {
+	scanner := bufio.NewScanner(rd)
+	var loop []int
+	var group *MeshGroup
+	for scanner.Scan() {
+		sb.lineNo += 1
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 {
+			continue
+		}
+		switch fields[0] {
+		case "solid":
+			sb.mesh.Groups = append(sb.mesh.Groups, MeshGroup{
+				Object: strings.Join(fields[1:], " "),
+				Start:  len(sb.indices) / 3,
+			})
+			group = &sb.mesh.Groups[len(sb.mesh.Groups)-1]
+		case "endsolid":
+			if group == nil {
+				return sb.errorf("endsolid without solid")
+			}
+			group.End = len(sb.indices) / 3
+			group = nil
+		case "facet", "outer":
+			loop = loop[:0]
+		case "vertex":
+			if len(fields) != 4 {
+				return sb.errorf("expected 3 coordinates, got %d", len(fields)-1)
+			}
+			var coords [3]float32
+			for i := range coords {
+				val, err := strconv.ParseFloat(fields[i+1], 32)
+				if err != nil {
+					return sb.errorf("could not convert `%s` to float", fields[i+1])
+				}
+				coords[i] = float32(val)
+			}
+			v := NewVec3(coords[0], coords[1], coords[2])
+			loop = append(loop, sb.weld(&v))
+		case "endloop":
+			if len(loop) < 3 {
+				return sb.errorf("a facet must have at least 3 vertices")
+			}
+			// like faces of PLY, polygons are added as triangle fans
+			for i := 2; i < len(loop); i++ {
+				sb.indices = append(sb.indices, loop[0], loop[i-1], loop[i])
+			}
+		case "endfacet":
+		default:
+			return sb.errorf("unexpected keyword %s", fields[0])
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return newErrorMesh(err.Error())
+	}
+	if group != nil {
+		return newErrorMesh("unexpected end of file")
+	}
+	return nil
+}
+
+// get the index of the vertex, adding it if it is new
+func (sb *stlBuilder) weld(v *Vec3) int {
+	idx, ok := sb.vertMap[*v]
+	if !ok {
+		idx = len(sb.mesh.Verts)
+		sb.vertMap[*v] = idx
+		sb.mesh.Verts = append(sb.mesh.Verts, *v)
+	}
+	return idx
+}
+
+func (sb *stlBuilder) finish() {
+	m := sb.mesh
+	m.Indices = make([]uint32, len(sb.indices))
+	for i, idx := range sb.indices {
+		m.Indices[i] = uint32(idx)
+	}
+}
+
+// Write a mesh as STL file
+//
+// The normals of the facets are calculated from the triangles.
+func WriteSTL(w io.Writer, m *Mesh, opt *STLWriteOptions) error {
+	if opt == nil {
+		opt = NewSTLDefaultOptions()
+	}
+	if err := m.checkTris(); err != nil {
+		return err
+	}
+	wr := bufio.NewWriter(w)
+	if opt.Binary {
+		writeSTLBinary(wr, m, opt)
+	} else {
+		writeSTLASCII(wr, m, opt)
+	}
+	return wr.Flush()
+}
+
+// the unit normal of a triangle, zero for degenerated ones
+func facetNormal(tri *Triangle, n *Vec3) {
+	var e1, e2 Vec3
+	Sub3(tri.P2, tri.P1, &e1)
+	Sub3(tri.P3, tri.P1, &e2)
+	Cross3(&e1, &e2, n)
+	if l := n.Length(); l > 0 {
+		*n = *n.Scale(1 / l)
+	}
+}
+
+func writeSTLBinary(wr *bufio.Writer, m *Mesh, opt *STLWriteOptions) {
+	var buf [stlTriangleSize]byte
+	var header [stlHeaderSize]byte
+	copy(header[:], opt.Name)
+	wr.Write(header[:])
+	binary.LittleEndian.PutUint32(buf[:4], uint32(m.TriCount()))
+	wr.Write(buf[:4])
+	for i, cnt := 0, m.TriCount(); i < cnt; i++ {
+		tri := m.Tri(i)
+		var n Vec3
+		facetNormal(&tri, &n)
+		for j, v := range [4]*Vec3{&n, tri.P1, tri.P2, tri.P3} {
+			binary.LittleEndian.PutUint32(buf[12*j:], math.Float32bits(v.X))
+			binary.LittleEndian.PutUint32(buf[12*j+4:], math.Float32bits(v.Y))
+			binary.LittleEndian.PutUint32(buf[12*j+8:], math.Float32bits(v.Z))
+		}
+		binary.LittleEndian.PutUint16(buf[48:], 0)
+		wr.Write(buf[:])
+	}
+}
+
+func writeSTLASCII(wr *bufio.Writer, m *Mesh, opt *STLWriteOptions) {
+	wr.WriteString("solid " + opt.Name + "\n")
+	for i, cnt := 0, m.TriCount(); i < cnt; i++ {
+		tri := m.Tri(i)
+		var n Vec3
+		facetNormal(&tri, &n)
+		wr.WriteString("facet normal " + formatFloat(n.X) + " " + formatFloat(n.Y) + " " + formatFloat(n.Z) + "\n")
+		wr.WriteString(" outer loop\n")
+		for _, v := range [3]*Vec3{tri.P1, tri.P2, tri.P3} {
+			wr.WriteString("  vertex " + formatFloat(v.X) + " " + formatFloat(v.Y) + " " + formatFloat(v.Z) + "\n")
+		}
+		wr.WriteString(" endloop\n")
+		wr.WriteString("endfacet\n")
+	}
+	wr.WriteString("endsolid " + opt.Name + "\n")
+}
@@ -0,0 +1,188 @@
+package vec32
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"strings"
+	"testing"
+)
+
+const stlTwoSolids = `solid first
+facet normal 0 0 1
+ outer loop
+  vertex 0 0 0
+  vertex 1 0 0
+  vertex 1 1 0
+ endloop
+endfacet
+facet normal 0 0 1
+ outer loop
+  vertex 0 0 0
+  vertex 1 1 0
+  vertex 0 1 0
+ endloop
+endfacet
+endsolid first
+solid second
+facet normal 0 0 1
+ outer loop
+  vertex 0 0 1
+  vertex 1 0 1
+  vertex 1 1 1
+  vertex 0 1 1
+ endloop
+endfacet
+endsolid
+`
+
+func TestReadSTLASCII(t *testing.T) {
+	m, err := ReadSTL(strings.NewReader(stlTwoSolids))
+	if err != nil {
+		t.Errorf("error on reading STL: %s", err.Error())
+		return
+	}
+	if len(m.Verts) != 8 || m.TriCount() != 4 || m.Tris != nil {
+		t.Errorf("expected 8 verts and 4 tris, got %d and %d", len(m.Verts), m.TriCount())
+		return
+	}
+	if m.Indices[0] != m.Indices[3] || m.Indices[2] != m.Indices[4] {
+		t.Errorf("vertices are not welded")
+	}
+	var groups = []MeshGroup{
+		{Object: "first", Start: 0, End: 2},
+		{Object: "second", Start: 2, End: 4},
+	}
+	if len(m.Groups) != 2 || m.Groups[0] != groups[0] || m.Groups[1] != groups[1] {
+		t.Errorf("wrong groups %v", m.Groups)
+	}
+}
+
+func TestReadSTLErrors(t *testing.T) {
+	var cases = []struct {
+		inputStr string
+		errorRsp string
+	}{
+		{"solid x\nendsolid\n", ""},
+		{"solid x\nfacet normal 0 0 1\nouter loop\nvertex 0 0\n", "line 4: expected 3 coordinates, got 2"},
+		{"solid x\nfacet normal 0 0 1\nouter loop\nvertex 0 0 a\n", "line 4: could not convert `a` to float"},
+		{"solid x\nfacet normal 0 0 1\nouter loop\nvertex 0 0 0\nendloop\n", "line 5: a facet must have at least 3 vertices"},
+		{"solid x\nfacet normal 0 0 1\nfoo\n", "line 3: unexpected keyword foo"},
+		{"solid x\nfacet normal 0 0 1\n", "unexpected end of file"},
+		{"", "unexpected end of file"},
+		{"solid but binary", "unexpected end of file"},
+	}
+	for i, tc := range cases {
+		_, err := ReadSTL(strings.NewReader(tc.inputStr))
+		errString := ""
+		if err != nil {
+			errString = err.Error()
+		}
+		if errString != tc.errorRsp {
+			t.Errorf("tc %d: expected error \"%s\", got \"%s\"", i+1, tc.errorRsp, errString)
+		}
+	}
+}
+
+func TestReadSTLBinarySolid(t *testing.T) {
+	m := &Mesh{Verts: []Vec3{NewVec3(0, 0, 0), NewVec3(1, 0, 0), NewVec3(0, 1, 0)}, Indices: []uint32{0, 1, 2}}
+	var buf bytes.Buffer
+	// the keywords of ASCII files in the header of a binary one
+	if err := WriteSTL(&buf, m, &STLWriteOptions{Binary: true, Name: "solid x facet endsolid"}); err != nil {
+		t.Fatalf("unexpected error %s", err.Error())
+	}
+	data := buf.Bytes()
+	// with Len() and as a plain io.Seeker
+	seeker := struct{ io.ReadSeeker }{bytes.NewReader(data)}
+	for _, r := range []io.Reader{bytes.NewBuffer(data), strings.NewReader(string(data)), seeker} {
+		m2, err := ReadSTL(r)
+		if err != nil {
+			t.Errorf("%T: unexpected error %s", r, err.Error())
+			continue
+		}
+		testMeshGeometry(t, 0, m, m2)
+	}
+}
+
+func TestSTLRoundTrip(t *testing.T) {
+	var files = []string{
+		"paulbourke.net.sample1.ply",
+		"two_cubes.ply",
+		"people.sc.fsu.edu.helix.ply",
+	}
+	for i, file := range files {
+		m, _ := getMesh(t, i, file)
+		if m == nil {
+			continue
+		}
+		for _, bin := range []bool{true, false} {
+			var buf bytes.Buffer
+			// binary headers starting with solid must not confuse the reader
+			if err := WriteSTL(&buf, m, &STLWriteOptions{Binary: bin, Name: "solid"}); err != nil {
+				t.Errorf("tc %d: error on writing: %s", i, err.Error())
+				continue
+			}
+			if bin && buf.Len() != 84+50*m.TriCount() {
+				t.Errorf("tc %d: wrong size of binary file: %d", i, buf.Len())
+			}
+			m2, err := ReadSTL(&buf)
+			if err != nil {
+				t.Errorf("tc %d (binary: %t): error on reading back: %s", i, bin, err.Error())
+				continue
+			}
+			testMeshGeometry(t, i, m, m2)
+			if len(m2.Verts) != len(m.Verts) {
+				t.Errorf("tc %d: expected %d welded verts, got %d", i, len(m.Verts), len(m2.Verts))
+			}
+		}
+	}
+}
+
+func TestWriteSTLNormals(t *testing.T) {
+	m := &Mesh{Verts: []Vec3{NewVec3(0, 0, 0), NewVec3(2, 0, 0), NewVec3(0, 2, 0)}}
+	m.Tris = []Triangle{
+		{&m.Verts[0], &m.Verts[1], &m.Verts[2]},
+		{&m.Verts[0], &m.Verts[0], &m.Verts[0]},
+	}
+	var buf bytes.Buffer
+	WriteSTL(&buf, m, nil)
+	data := buf.Bytes()
+	var n [2][3]float32
+	binary.Read(bytes.NewReader(data[84:96]), binary.LittleEndian, &n[0])
+	binary.Read(bytes.NewReader(data[134:146]), binary.LittleEndian, &n[1])
+	if n[0] != [3]float32{0, 0, 1} || n[1] != [3]float32{0, 0, 0} {
+		t.Errorf("wrong normals %v", n)
+	}
+	if !bytes.HasPrefix(data, []byte("vec32\x00")) {
+		t.Errorf("wrong header %q", data[:8])
+	}
+}
+
+func TestWriteSTLErrors(t *testing.T) {
+	m := &Mesh{Verts: make([]Vec3, 2), Indices: []uint32{0, 1, 2}}
+	var buf bytes.Buffer
+	for _, binary := range []bool{true, false} {
+		if err := WriteSTL(&buf, m, &STLWriteOptions{Binary: binary}); err == nil ||
+			err.Error() != "triangle 0 does not point into the vertices" {
+			t.Errorf("expected error on invalid index, got %v", err)
+		}
+	}
+	if buf.Len() != 0 {
+		t.Errorf("nothing should be written, got %d bytes", buf.Len())
+	}
+}
+
+// compare the positions of the triangles, ignoring the order of vertices
+func testMeshGeometry(t *testing.T, tc int, exp, cur *Mesh) {
+	if exp.TriCount() != cur.TriCount() {
+		t.Errorf("tc %d: expected %d tris, got %d", tc, exp.TriCount(), cur.TriCount())
+		return
+	}
+	for i, n := 0, exp.TriCount(); i < n; i++ {
+		e, c := exp.Tri(i), cur.Tri(i)
+		if !e.P1.IsEqual(c.P1) || !e.P2.IsEqual(c.P2) || !e.P3.IsEqual(c.P3) {
+			t.Errorf("tc %d: tri %d differs: expected %s, got %s", tc, i, e.String(), c.String())
+			return
+		}
+	}
+}
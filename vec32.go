@@ -27,6 +27,27 @@ type Vec3 struct {
 	X, Y, Z, pad float32
 }
 
+// A four dimensional Vector, e.g. homogeneous coordinates
+type Vec4 struct {
+	X, Y, Z, W float32
+}
+
+// A quaternion (X, Y, Z imaginary, W real part)
+//
+// Rotations are represented by unit quaternions.
+type Quat struct {
+	X, Y, Z, W float32
+}
+
+// A 3x3 matrix, stored column-major (M[col][row])
+type Mat3 [3][3]float32
+
+// A 4x4 matrix, stored column-major (M[col][row])
+//
+// Each column has the size of a Vec3 (including its padding), so columns
+// can be loaded with a single SSE instruction.
+type Mat4 [4][4]float32
+
 // Triangle in R3
 type Triangle struct {
 	P1, P2, P3 *Vec3
@@ -42,7 +63,47 @@ type Ray struct {
 type Mesh struct {
 	// The vertices we have
 	Verts []Vec3
-	Tris  []Triangle
+
+	// Vertex indices, 3 per triangle, use Tri(i) for a triangle
+	Indices []uint32
+
+	// Legacy triangles pointing into Verts
+	//
+	// Only read if Indices is empty, SyncIndices() converts them. Nothing
+	// in the package fills them in, see SyncTris().
+	Tris []Triangle
+
+	// Optional quads the triangles were made of, 4 vertex indices each
+	//
+	// Either empty or one per pair of triangles: quad i is split into the
+	// triangles 2i and 2i+1. Methods changing the triangles drop them.
+	Quads []uint32
+
+	// Optional attributes per vertex, either empty or as long as Verts
+	Normals []Vec3
+	UVs     []Vec2
+	Colors  []Color
+
+	// Optional unit normals per triangle, either empty or one per triangle
+	FaceNormals []Vec3
+
+	// Optional named ranges of the triangles and the material libraries they use
+	Groups       []MeshGroup
+	MaterialLibs []string
+}
+
+// A range of triangles [Start, End) of a mesh sharing object, group and material
+type MeshGroup struct {
+	Object   string
+	Name     string
+	Material string
+	Start    int
+	End      int
+}
+
+// A RGBA-color, components are in [0, 1]
+type Color struct {
+	R, G, B, A float32
 }
 
 // Box othogonal to axis
@@ -57,10 +118,20 @@ func (b *OrthoBox) String() string {
 	return fmt.Sprintf("{%s->%s}", b.P0.String(), b.P1.String())
 }
 
+// Result of a ray-triangle intersection
 type Intersection struct {
 	t, u, v float32
 }
 
+// distance along the ray
+func (i *Intersection) T() float32 { return i.t }
+
+// first barycentric coordinate (weight of P2)
+func (i *Intersection) U() float32 { return i.u }
+
+// second barycentric coordinate (weight of P3)
+func (i *Intersection) V() float32 { return i.v }
+
 // a generic object you can see
 type Object interface {
 	OrthoBox() OrthoBox